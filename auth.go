@@ -0,0 +1,155 @@
+package mazzaroth
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kochavalabs/crypto"
+	"github.com/pkg/errors"
+)
+
+// Headers of a signed request.
+const (
+	HeaderPublicKey = "X-Mazzaroth-Public-Key"
+	HeaderTimestamp = "X-Mazzaroth-Timestamp"
+	HeaderSignature = "X-Mazzaroth-Signature"
+)
+
+// tokenRefreshMargin is how long before its expiry a refreshing token
+// source fetches a new token.
+const tokenRefreshMargin = 30 * time.Second
+
+// TokenSource supplies the bearer token of every request.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token calls f.
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// RefreshFunc fetches a new token along with the time it expires at.
+type RefreshFunc func(ctx context.Context) (string, time.Time, error)
+
+// RefreshingTokenSource reuses a token until shortly before it expires and
+// then refreshes it.
+type RefreshingTokenSource struct {
+	refresh RefreshFunc
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewRefreshingTokenSource creates a token source refreshing its token with
+// refresh.
+func NewRefreshingTokenSource(refresh RefreshFunc) *RefreshingTokenSource {
+	return &RefreshingTokenSource{refresh: refresh}
+}
+
+// Token returns the current token, refreshing it if it is about to expire.
+func (s *RefreshingTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Until(s.expires) > tokenRefreshMargin {
+		return s.token, nil
+	}
+	token, expires, err := s.refresh(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to refresh token")
+	}
+	s.token, s.expires = token, expires
+	return token, nil
+}
+
+// authOptions are the credentials a client adds to every request.
+type authOptions struct {
+	tokens       TokenSource
+	apiKeyHeader string
+	apiKey       string
+	signingKey   ed25519.PrivateKey
+}
+
+// apply adds the credentials to req, whose encoded body is body.
+func (a *authOptions) apply(ctx context.Context, req *http.Request, body []byte) error {
+	if a.tokens != nil {
+		token, err := a.tokens.Token(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if a.apiKeyHeader != "" {
+		req.Header.Set(a.apiKeyHeader, a.apiKey)
+	}
+	if a.signingKey != nil {
+		signRequest(req, body, a.signingKey, time.Now())
+	}
+	return nil
+}
+
+// signedPayload returns the bytes a request signature covers: its method,
+// path with query, timestamp and the sha3 hash of its body.
+func signedPayload(method string, uri string, timestamp string, body []byte) []byte {
+	hasher := &crypto.Sha3_256Hasher{}
+	return []byte(method + "\n" + uri + "\n" + timestamp + "\n" + hex.EncodeToString(hasher.Hash(body)))
+}
+
+// signRequest signs req with key at now.
+func signRequest(req *http.Request, body []byte, key ed25519.PrivateKey, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := ed25519.Sign(key, signedPayload(req.Method, req.URL.RequestURI(), timestamp, body))
+
+	req.Header.Set(HeaderPublicKey, hex.EncodeToString(key.Public().(ed25519.PublicKey)))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, hex.EncodeToString(signature))
+}
+
+// VerifyRequest checks the signature of a request received from a client
+// configured with WithRequestSigning, and that it was signed at most maxSkew
+// from now. It returns the public key the request was signed with, which
+// the caller has to check is allowed. The body of req is read and replaced.
+func VerifyRequest(req *http.Request, maxSkew time.Duration) (ed25519.PublicKey, error) {
+	publicKey, err := hex.DecodeString(req.Header.Get(HeaderPublicKey))
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.Wrap(ErrInvalidRequestSignature, "invalid public key")
+	}
+	signature, err := hex.DecodeString(req.Header.Get(HeaderSignature))
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidRequestSignature, "invalid signature")
+	}
+	timestamp := req.Header.Get(HeaderTimestamp)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidRequestSignature, "invalid timestamp")
+	}
+	if skew := time.Since(time.Unix(unix, 0)); skew > maxSkew || skew < -maxSkew {
+		return nil, errors.Wrap(ErrInvalidRequestSignature, "request timestamp out of range")
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read the body")
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if !ed25519.Verify(publicKey, signedPayload(req.Method, req.URL.RequestURI(), timestamp, body), signature) {
+		return nil, errors.Wrap(ErrInvalidRequestSignature, "signature does not match the request")
+	}
+	return publicKey, nil
+}
@@ -0,0 +1,107 @@
+package mazzaroth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+func TestAuthHeaders(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		(&xdrNode{}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client, err := NewMazzarothClient(WithAddress(server.URL), WithBearerToken("token"), WithAPIKey("X-Api-Key", "key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReceiptLookup(context.Background(), "ab", "01"); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("Authorization") != "Bearer token" || header.Get("X-Api-Key") != "key" {
+		t.Fatalf("unexpected headers: %v", header)
+	}
+
+	errRefresh := errors.New("refresh failed")
+	client, err = NewMazzarothClient(WithAddress(server.URL), WithTokenSource(TokenSourceFunc(func(ctx context.Context) (string, error) {
+		return "", errRefresh
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReceiptLookup(context.Background(), "ab", "01"); errors.Cause(err) != errRefresh {
+		t.Fatalf("expected the token source error, got: %v", err)
+	}
+}
+
+func TestRefreshingTokenSource(t *testing.T) {
+	refreshes := 0
+	expires := time.Now().Add(time.Hour)
+	source := NewRefreshingTokenSource(func(ctx context.Context) (string, time.Time, error) {
+		refreshes++
+		return "token", expires, nil
+	})
+	ctx := context.Background()
+
+	source.Token(ctx)
+	source.Token(ctx)
+	if refreshes != 1 {
+		t.Fatalf("expected the token to be reused, got %d refreshes", refreshes)
+	}
+
+	expires = time.Now().Add(tokenRefreshMargin / 2)
+	source.token = ""
+	source.Token(ctx)
+	source.Token(ctx)
+	if refreshes != 3 {
+		t.Fatalf("expected a token about to expire to be refreshed, got %d refreshes", refreshes)
+	}
+}
+
+func TestRequestSigning(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var verifyErr error
+	var signer ed25519.PublicKey
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signer, verifyErr = VerifyRequest(r, time.Minute)
+		(&xdrNode{}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client, err := NewMazzarothClient(WithAddress(server.URL), WithRequestSigning(privateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &xdr.Transaction{Data: xdr.Data{Nonce: 1, Category: xdr.Category{Type: xdr.CategoryTypeDELETE}}}
+	if _, _, err := client.TransactionSubmit(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	if verifyErr != nil || !signer.Equal(publicKey) {
+		t.Fatalf("expected a valid signature by the key, got: %v", verifyErr)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/channels/ab/transactions", nil)
+	signRequest(req, []byte("body"), privateKey, time.Now())
+	if _, err := VerifyRequest(req, time.Minute); errors.Cause(err) != ErrInvalidRequestSignature {
+		t.Fatalf("expected a tampered body to fail, got: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/channels/ab/blocks/height", nil)
+	signRequest(req, nil, privateKey, time.Now().Add(-time.Hour))
+	if _, err := VerifyRequest(req, time.Minute); errors.Cause(err) != ErrInvalidRequestSignature {
+		t.Fatalf("expected an old request to fail, got: %v", err)
+	}
+}
@@ -0,0 +1,430 @@
+package mazzaroth
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+var _ Client = &CachingClient{}
+
+// CachingClient wraps a Client and caches the results of lookups that can
+// not change once a node returned them: finalized blocks and block headers
+// looked up by height or hash, transactions and finalized receipts. They are
+// kept xdr encoded in a size bounded LRU cache in memory and, if configured,
+// on disk. Lookups of other block ids, like "latest", and of pending blocks
+// and receipts always go to the wrapped client.
+//
+// The block height of a channel is only cached for a short TTL. Channel
+// abis are cached for a TTL as well and dropped whenever a DEPLOY or
+// DELETE transaction is submitted through the client, so an abi is never
+// served across a deploy made through it. A deploy submitted by any other
+// client is only seen once the cached abi expires.
+type CachingClient struct {
+	client Client
+	lru    *lruCache
+	dir    string
+
+	heightTTL time.Duration
+	abiTTL    time.Duration
+
+	mu      sync.Mutex
+	heights map[string]cachedHeight
+	abis    map[string]cachedAbi
+	deploys map[string]uint64
+}
+
+type cachedHeight struct {
+	height  xdr.BlockHeight
+	expires time.Time
+}
+
+type cachedAbi struct {
+	abi     []byte
+	expires time.Time
+}
+
+// cachingClientOptions config options for a caching client
+type cachingClientOptions struct {
+	size      int
+	dir       string
+	heightTTL time.Duration
+	abiTTL    time.Duration
+}
+
+// CacheOptions interface for applying caching client options
+type CacheOptions interface {
+	apply(*cachingClientOptions)
+}
+
+type funcCacheOption struct {
+	f func(*cachingClientOptions)
+}
+
+func (fco *funcCacheOption) apply(opt *cachingClientOptions) {
+	fco.f(opt)
+}
+
+func newFuncCacheOption(f func(*cachingClientOptions)) *funcCacheOption {
+	return &funcCacheOption{
+		f: f,
+	}
+}
+
+// WithCacheSize used to set the number of results the caching client keeps
+// in memory
+func WithCacheSize(size int) CacheOptions {
+	return newFuncCacheOption(func(o *cachingClientOptions) {
+		o.size = size
+	})
+}
+
+// WithDiskCache used to also keep cached results in a directory, which is
+// not size bounded
+func WithDiskCache(dir string) CacheOptions {
+	return newFuncCacheOption(func(o *cachingClientOptions) {
+		o.dir = dir
+	})
+}
+
+// WithHeightTTL used to set how long the caching client serves a block
+// height, zero disables caching heights
+func WithHeightTTL(ttl time.Duration) CacheOptions {
+	return newFuncCacheOption(func(o *cachingClientOptions) {
+		o.heightTTL = ttl
+	})
+}
+
+// WithAbiTTL used to set how long the caching client serves a channel abi,
+// zero disables caching abis
+func WithAbiTTL(ttl time.Duration) CacheOptions {
+	return newFuncCacheOption(func(o *cachingClientOptions) {
+		o.abiTTL = ttl
+	})
+}
+
+// defaultCacheOption defines a set of default options for the caching client
+func defaultCacheOption() *cachingClientOptions {
+	return &cachingClientOptions{
+		size:      1024,
+		heightTTL: time.Second,
+		abiTTL:    time.Minute,
+	}
+}
+
+// NewCachingClient creates a caching client for the results of client.
+func NewCachingClient(client Client, options ...CacheOptions) (*CachingClient, error) {
+	cacheOptions := defaultCacheOption()
+	for _, opt := range options {
+		opt.apply(cacheOptions)
+	}
+
+	if cacheOptions.dir != "" {
+		if err := os.MkdirAll(cacheOptions.dir, 0755); err != nil {
+			return nil, errors.Wrap(err, "unable to create cache directory")
+		}
+	}
+
+	return &CachingClient{
+		client:    client,
+		lru:       newLRUCache(cacheOptions.size),
+		dir:       cacheOptions.dir,
+		heightTTL: cacheOptions.heightTTL,
+		abiTTL:    cacheOptions.abiTTL,
+		heights:   make(map[string]cachedHeight),
+		abis:      make(map[string]cachedAbi),
+		deploys:   make(map[string]uint64),
+	}, nil
+}
+
+// get decodes the cached result of key into v, returning false if it is not
+// cached.
+func (cc *CachingClient) get(key string, v encoding.BinaryUnmarshaler) bool {
+	b, ok := cc.lru.get(key)
+	if !ok && cc.dir != "" {
+		var err error
+		b, err = os.ReadFile(cc.path(key))
+		if err != nil {
+			return false
+		}
+		cc.lru.put(key, b)
+		ok = true
+	}
+	return ok && v.UnmarshalBinary(b) == nil
+}
+
+// put caches v as the result of key. Results that fail to encode or to be
+// written to disk are simply not cached.
+func (cc *CachingClient) put(key string, v encoding.BinaryMarshaler) {
+	b, err := v.MarshalBinary()
+	if err != nil {
+		return
+	}
+	cc.lru.put(key, b)
+	if cc.dir != "" {
+		tmp := cc.path(key) + ".tmp"
+		if os.WriteFile(tmp, b, 0644) == nil {
+			os.Rename(tmp, cc.path(key))
+		}
+	}
+}
+
+// path returns the file a result is cached in on disk.
+func (cc *CachingClient) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(cc.dir, hex.EncodeToString(sum[:]))
+}
+
+func cacheKey(kind, channelID, id string) string {
+	return kind + "/" + channelID + "/" + id
+}
+
+// immutableBlockID returns whether blockID always refers to the same block,
+// which is the case for heights and header hashes.
+func immutableBlockID(blockID string) bool {
+	if _, err := strconv.ParseUint(blockID, 10, 64); err == nil {
+		return true
+	}
+	_, err := xdr.IDFromHexString(blockID)
+	return err == nil
+}
+
+// finalized returns whether a receipt of status can not change anymore.
+func finalized(status xdr.Status) bool {
+	switch status {
+	case xdr.StatusSUCCESS, xdr.StatusFAILURE, xdr.StatusFINALIZED:
+		return true
+	}
+	return false
+}
+
+// BlockHeaderLookup returns a block header, from the cache if it is looked
+// up by height or hash and was finalized.
+func (cc *CachingClient) BlockHeaderLookup(ctx context.Context, channelID string, blockID string) (*xdr.BlockHeader, error) {
+	if !immutableBlockID(blockID) {
+		return cc.client.BlockHeaderLookup(ctx, channelID, blockID)
+	}
+
+	key := cacheKey("blockheader", channelID, blockID)
+	header := &xdr.BlockHeader{}
+	if cc.get(key, header) {
+		return header, nil
+	}
+
+	header, err := cc.client.BlockHeaderLookup(ctx, channelID, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if finalized(header.Status) {
+		cc.put(key, header)
+	}
+	return header, nil
+}
+
+// BlockHeaderList calls the wrapped client, lists are not cached.
+func (cc *CachingClient) BlockHeaderList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.BlockHeader, error) {
+	return cc.client.BlockHeaderList(ctx, channelID, blockHeight, number)
+}
+
+// BlockHeight returns the block height of a channel, from the cache if it
+// was fetched less than the height TTL ago.
+func (cc *CachingClient) BlockHeight(ctx context.Context, channelID string) (*xdr.BlockHeight, error) {
+	cc.mu.Lock()
+	cached, ok := cc.heights[channelID]
+	cc.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		height := cached.height
+		return &height, nil
+	}
+
+	height, err := cc.client.BlockHeight(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if cc.heightTTL > 0 {
+		cc.mu.Lock()
+		cc.heights[channelID] = cachedHeight{height: *height, expires: time.Now().Add(cc.heightTTL)}
+		cc.mu.Unlock()
+	}
+	return height, nil
+}
+
+// BlockLookup returns a block, from the cache if it is looked up by height
+// or hash and was finalized.
+func (cc *CachingClient) BlockLookup(ctx context.Context, channelID string, blockID string) (*xdr.Block, error) {
+	if !immutableBlockID(blockID) {
+		return cc.client.BlockLookup(ctx, channelID, blockID)
+	}
+
+	key := cacheKey("block", channelID, blockID)
+	block := &xdr.Block{}
+	if cc.get(key, block) {
+		return block, nil
+	}
+
+	block, err := cc.client.BlockLookup(ctx, channelID, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if finalized(block.Header.Status) {
+		cc.put(key, block)
+	}
+	return block, nil
+}
+
+// BlockList calls the wrapped client, lists are not cached.
+func (cc *CachingClient) BlockList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.Block, error) {
+	return cc.client.BlockList(ctx, channelID, blockHeight, number)
+}
+
+// ChannelAbi returns the abi of a channel, from the cache if it was
+// fetched less than the abi TTL ago and no contract was deployed or deleted
+// through the client since.
+func (cc *CachingClient) ChannelAbi(ctx context.Context, channelID string) (*xdr.Abi, error) {
+	cc.mu.Lock()
+	cached, ok := cc.abis[channelID]
+	deploys := cc.deploys[channelID]
+	cc.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		abi := &xdr.Abi{}
+		if abi.UnmarshalBinary(cached.abi) == nil {
+			return abi, nil
+		}
+	}
+
+	abi, err := cc.client.ChannelAbi(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := abi.MarshalBinary()
+	if cc.abiTTL > 0 && err == nil {
+		cc.mu.Lock()
+		// an abi fetched while a deploy was submitted may already be stale
+		if cc.deploys[channelID] == deploys {
+			cc.abis[channelID] = cachedAbi{abi: b, expires: time.Now().Add(cc.abiTTL)}
+		}
+		cc.mu.Unlock()
+	}
+	return abi, nil
+}
+
+// ReceiptLookup returns a receipt, from the cache if it was finalized.
+func (cc *CachingClient) ReceiptLookup(ctx context.Context, channelID string, transactionID string) (*xdr.Receipt, error) {
+	key := cacheKey("receipt", channelID, transactionID)
+	receipt := &xdr.Receipt{}
+	if cc.get(key, receipt) {
+		return receipt, nil
+	}
+
+	receipt, err := cc.client.ReceiptLookup(ctx, channelID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if finalized(receipt.Status) {
+		cc.put(key, receipt)
+	}
+	return receipt, nil
+}
+
+// TransactionLookup returns a transaction, from the cache if possible.
+func (cc *CachingClient) TransactionLookup(ctx context.Context, channelID string, transactionID string) (*xdr.Transaction, error) {
+	key := cacheKey("transaction", channelID, transactionID)
+	transaction := &xdr.Transaction{}
+	if cc.get(key, transaction) {
+		return transaction, nil
+	}
+
+	transaction, err := cc.client.TransactionLookup(ctx, channelID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	cc.put(key, transaction)
+	return transaction, nil
+}
+
+// TransactionSubmit submits a transaction through the wrapped client. A
+// DEPLOY or DELETE transaction drops the cached abi of its channel, whether
+// or not the submission succeeded.
+func (cc *CachingClient) TransactionSubmit(ctx context.Context, transaction *xdr.Transaction) (*xdr.ID, *xdr.Receipt, error) {
+	category := transaction.Data.Category.Type
+	if category != xdr.CategoryTypeDEPLOY && category != xdr.CategoryTypeDELETE {
+		return cc.client.TransactionSubmit(ctx, transaction)
+	}
+
+	channelID := hex.EncodeToString(transaction.Data.ChannelID[:])
+	invalidate := func() {
+		cc.mu.Lock()
+		delete(cc.abis, channelID)
+		cc.deploys[channelID]++
+		cc.mu.Unlock()
+	}
+	invalidate()
+	defer invalidate()
+
+	return cc.client.TransactionSubmit(ctx, transaction)
+}
+
+// lruCache is a size bounded cache of encoded results evicting the least
+// recently used result first.
+type lruCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+func (c *lruCache) put(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	for c.order.Len() > c.size {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*lruEntry).key)
+	}
+}
@@ -0,0 +1,254 @@
+package mazzaroth
+
+import (
+	"context"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+// countingNode counts the calls made to a testNode.
+type countingNode struct {
+	*testNode
+	calls map[string]int
+	abi   xdr.Abi
+}
+
+func newCountingNode(t *testing.T) *countingNode {
+	return &countingNode{testNode: newTestNode(t, 3), calls: make(map[string]int)}
+}
+
+func (n *countingNode) BlockHeight(ctx context.Context, channelID string) (*xdr.BlockHeight, error) {
+	n.calls["BlockHeight"]++
+	return n.testNode.BlockHeight(ctx, channelID)
+}
+
+func (n *countingNode) BlockLookup(ctx context.Context, channelID string, blockID string) (*xdr.Block, error) {
+	n.calls["BlockLookup"]++
+	if blockID == "latest" {
+		blockID = strconv.Itoa(len(n.blocks) - 1)
+	}
+	return n.testNode.BlockLookup(ctx, channelID, blockID)
+}
+
+func (n *countingNode) ReceiptLookup(ctx context.Context, channelID string, transactionID string) (*xdr.Receipt, error) {
+	n.calls["ReceiptLookup"]++
+	return n.testNode.ReceiptLookup(ctx, channelID, transactionID)
+}
+
+func (n *countingNode) ChannelAbi(ctx context.Context, channelID string) (*xdr.Abi, error) {
+	n.calls["ChannelAbi"]++
+	abi := n.abi
+	return &abi, nil
+}
+
+func (n *countingNode) TransactionSubmit(ctx context.Context, transaction *xdr.Transaction) (*xdr.ID, *xdr.Receipt, error) {
+	n.calls["TransactionSubmit"]++
+	if transaction.Data.Category.Type == xdr.CategoryTypeDEPLOY {
+		n.abi = transaction.Data.Category.Contract.Abi
+	}
+	return &xdr.ID{}, nil, nil
+}
+
+func TestCachingClientLookups(t *testing.T) {
+	node := newCountingNode(t)
+	cc, err := NewCachingClient(node, WithCacheSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, id := range []string{"0", "1", "0", "2", "1"} {
+		block, err := cc.BlockLookup(ctx, "channel", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := node.blocks[id[0]-'0'].Header; block.Header != want {
+			t.Fatalf("unexpected block for %s: %v", id, block.Header)
+		}
+	}
+	// 0 and 1 are fetched once, 2 evicts 1, which is then fetched again
+	if node.calls["BlockLookup"] != 4 {
+		t.Fatalf("expected 4 block lookups, got: %d", node.calls["BlockLookup"])
+	}
+
+	if _, err := cc.BlockLookup(ctx, "channel", "9"); err != ErrNotFound {
+		t.Fatalf("expected not found, got: %v", err)
+	}
+	if _, err := cc.BlockLookup(ctx, "channel", "9"); err != ErrNotFound {
+		t.Fatalf("expected not found, got: %v", err)
+	}
+	if node.calls["BlockLookup"] != 6 {
+		t.Fatalf("expected errors not to be cached, got %d lookups", node.calls["BlockLookup"])
+	}
+
+	// a returned result can be modified without affecting the cache
+	block, _ := cc.BlockLookup(ctx, "channel", "2")
+	block.Header.BlockHeight = 42
+	block, _ = cc.BlockLookup(ctx, "channel", "2")
+	if block.Header.BlockHeight != 2 {
+		t.Fatalf("cached block was modified: %v", block.Header)
+	}
+}
+
+func TestCachingClientMutableResults(t *testing.T) {
+	node := newCountingNode(t)
+	cc, err := NewCachingClient(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	cc.BlockLookup(ctx, "channel", "latest")
+	node.blocks = append(node.blocks, xdr.Block{Header: xdr.BlockHeader{BlockHeight: 3, Status: xdr.StatusFINALIZED}})
+	block, err := cc.BlockLookup(ctx, "channel", "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Header.BlockHeight != 3 || node.calls["BlockLookup"] != 2 {
+		t.Fatalf("expected the latest block not to be cached, got %v after %d lookups", block.Header, node.calls["BlockLookup"])
+	}
+
+	node.blocks[3].Header.Status = xdr.StatusPENDING
+	cc.BlockLookup(ctx, "channel", "3")
+	cc.BlockHeaderLookup(ctx, "channel", "3")
+	node.blocks[3].Header.Status = xdr.StatusFINALIZED
+	block, err = cc.BlockLookup(ctx, "channel", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := cc.BlockHeaderLookup(ctx, "channel", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Header.Status != xdr.StatusFINALIZED || header.Status != xdr.StatusFINALIZED {
+		t.Fatalf("expected the pending block not to be cached, got: %v %v", block.Header.Status, header.Status)
+	}
+
+	var id string
+	for key := range node.receipts {
+		id = key
+		break
+	}
+	node.receipts[id].Status = xdr.StatusPENDING
+	cc.ReceiptLookup(ctx, "channel", id)
+	node.receipts[id].Status = xdr.StatusSUCCESS
+	for i := 0; i < 2; i++ {
+		receipt, err := cc.ReceiptLookup(ctx, "channel", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if receipt.Status != xdr.StatusSUCCESS {
+			t.Fatalf("expected the pending receipt not to be cached, got: %v", receipt.Status)
+		}
+	}
+	if node.calls["ReceiptLookup"] != 2 {
+		t.Fatalf("expected the finalized receipt to be cached, got %d lookups", node.calls["ReceiptLookup"])
+	}
+}
+
+func TestCachingClientDisk(t *testing.T) {
+	node := newCountingNode(t)
+	dir := t.TempDir()
+	var id string
+	for key := range node.receipts {
+		id = key
+		break
+	}
+
+	cc, err := NewCachingClient(node, WithDiskCache(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cc.ReceiptLookup(context.Background(), "channel", id); err != nil {
+		t.Fatal(err)
+	}
+
+	cc, err = NewCachingClient(node, WithDiskCache(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := cc.ReceiptLookup(context.Background(), "channel", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(receipt.TransactionID[:]) != id || node.calls["ReceiptLookup"] != 1 {
+		t.Fatalf("expected the receipt from disk, got: %v after %d lookups", receipt, node.calls["ReceiptLookup"])
+	}
+}
+
+func TestCachingClientHeight(t *testing.T) {
+	node := newCountingNode(t)
+	cc, err := NewCachingClient(node, WithHeightTTL(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	cc.BlockHeight(ctx, "channel")
+	cc.BlockHeight(ctx, "channel")
+	if node.calls["BlockHeight"] != 1 {
+		t.Fatalf("expected a cached height, got %d calls", node.calls["BlockHeight"])
+	}
+	time.Sleep(60 * time.Millisecond)
+	cc.BlockHeight(ctx, "channel")
+	if node.calls["BlockHeight"] != 2 {
+		t.Fatalf("expected the height to expire, got %d calls", node.calls["BlockHeight"])
+	}
+}
+
+func TestCachingClientAbi(t *testing.T) {
+	node := newCountingNode(t)
+	node.abi = xdr.Abi{Functions: []xdr.FunctionSignature{{FunctionName: "old"}}}
+	cc, err := NewCachingClient(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	channelID := hex.EncodeToString(make([]byte, 32))
+
+	cc.ChannelAbi(ctx, channelID)
+	abi, _ := cc.ChannelAbi(ctx, channelID)
+	if node.calls["ChannelAbi"] != 1 || abi.Functions[0].FunctionName != "old" {
+		t.Fatalf("expected a cached abi, got %v after %d calls", abi, node.calls["ChannelAbi"])
+	}
+
+	deploy := &xdr.Transaction{Data: xdr.Data{Category: xdr.Category{
+		Type:     xdr.CategoryTypeDEPLOY,
+		Contract: &xdr.Contract{Abi: xdr.Abi{Functions: []xdr.FunctionSignature{{FunctionName: "new"}}}},
+	}}}
+	if _, _, err := cc.TransactionSubmit(ctx, deploy); err != nil {
+		t.Fatal(err)
+	}
+	abi, _ = cc.ChannelAbi(ctx, channelID)
+	if node.calls["ChannelAbi"] != 2 || abi.Functions[0].FunctionName != "new" {
+		t.Fatalf("expected the abi to be refetched after a deploy, got %v after %d calls", abi, node.calls["ChannelAbi"])
+	}
+}
+
+func TestCachingClientAbiExternalDeploy(t *testing.T) {
+	node := newCountingNode(t)
+	node.abi = xdr.Abi{Functions: []xdr.FunctionSignature{{FunctionName: "old"}}}
+	cc, err := NewCachingClient(node, WithAbiTTL(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	channelID := hex.EncodeToString(make([]byte, 32))
+
+	// a deploy made through another client is only seen once the abi expires
+	cc.ChannelAbi(ctx, channelID)
+	node.abi = xdr.Abi{Functions: []xdr.FunctionSignature{{FunctionName: "new"}}}
+	abi, _ := cc.ChannelAbi(ctx, channelID)
+	if abi.Functions[0].FunctionName != "old" {
+		t.Fatalf("expected the cached abi, got: %v", abi)
+	}
+	time.Sleep(60 * time.Millisecond)
+	abi, _ = cc.ChannelAbi(ctx, channelID)
+	if abi.Functions[0].FunctionName != "new" {
+		t.Fatalf("expected the abi to expire, got: %v", abi)
+	}
+}
@@ -14,6 +14,7 @@ type CallBuilder struct {
 	blockExpirationNumber uint64
 	functionName          string
 	arguments             []xdr.Argument
+	logger                Logger
 }
 
 // Call
@@ -37,8 +38,20 @@ func (cb *CallBuilder) Arguments(arguments ...xdr.Argument) *CallBuilder {
 	return cb
 }
 
+// Logger sets the logger that signing events are logged to
+func (cb *CallBuilder) Logger(logger Logger) *CallBuilder {
+	cb.logger = logger
+	return cb
+}
+
 // Sign
 func (cb *CallBuilder) Sign(pk ed25519.PrivateKey) (*xdr.Transaction, error) {
+	transaction, err := cb.sign(pk)
+	logSign(cb.logger, cb.sender, xdr.CategoryTypeCALL, cb.nonce, err)
+	return transaction, err
+}
+
+func (cb *CallBuilder) sign(pk ed25519.PrivateKey) (*xdr.Transaction, error) {
 	// check required values
 	if len(cb.functionName) <= 0 {
 		return nil, ErrEmptyFunctionName
@@ -0,0 +1,178 @@
+package mazzaroth
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitInterval is the period over which a closed circuit breaker counts
+// requests and failures before starting over.
+const circuitInterval = 10 * time.Second
+
+// CircuitState is the state of the circuit breaker of a node.
+type CircuitState int32
+
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every request with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets a single request through to probe whether the
+	// node recovered.
+	CircuitHalfOpen
+)
+
+var circuitStateNames = map[CircuitState]string{
+	CircuitClosed:   "CircuitClosed",
+	CircuitOpen:     "CircuitOpen",
+	CircuitHalfOpen: "CircuitHalfOpen",
+}
+
+// String returns the name of s.
+func (s CircuitState) String() string {
+	return circuitStateNames[s]
+}
+
+// MarshalText encodes s as its name.
+func (s CircuitState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// CircuitStateFunc is called with the address of a node whenever the state
+// of its circuit breaker changes.
+type CircuitStateFunc func(address string, from CircuitState, to CircuitState)
+
+// circuitConfig is the configuration of the circuit breakers of a client, a
+// zero ratio disables them.
+type circuitConfig struct {
+	ratio       float64
+	minRequests int
+	openTimeout time.Duration
+	onChange    CircuitStateFunc
+}
+
+// circuitBreaker tracks the failures of the requests made to a node. Once
+// at least minRequests were made during the current interval and the ratio
+// of failures among them reaches ratio, it opens. After openTimeout it
+// half-opens and lets a single request through, closing again if that
+// request succeeds and opening otherwise.
+type circuitBreaker struct {
+	address string
+	config  circuitConfig
+
+	mu       sync.Mutex
+	state    CircuitState
+	requests int
+	failures int
+	since    time.Time
+	probing  bool
+	changes  []CircuitState
+}
+
+func newCircuitBreaker(address string, config circuitConfig) *circuitBreaker {
+	return &circuitBreaker{
+		address: address,
+		config:  config,
+		since:   time.Now(),
+	}
+}
+
+// allow reports whether a request may be made to the node. A request
+// allowed by a half-open breaker is its probe.
+func (cb *circuitBreaker) allow() bool {
+	if cb.config.ratio <= 0 {
+		return true
+	}
+
+	cb.mu.Lock()
+	defer cb.unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.since) < cb.config.openTimeout {
+			return false
+		}
+		cb.transition(CircuitHalfOpen)
+		fallthrough
+	case CircuitHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record records the outcome of an allowed request.
+func (cb *circuitBreaker) record(failed bool) {
+	if cb.config.ratio <= 0 {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.unlock()
+
+	switch cb.state {
+	case CircuitHalfOpen:
+		cb.probing = false
+		if failed {
+			cb.transition(CircuitOpen)
+		} else {
+			cb.transition(CircuitClosed)
+		}
+	case CircuitClosed:
+		if time.Since(cb.since) > circuitInterval {
+			cb.requests, cb.failures, cb.since = 0, 0, time.Now()
+		}
+		cb.requests++
+		if failed {
+			cb.failures++
+		}
+		if cb.requests >= cb.config.minRequests && float64(cb.failures)/float64(cb.requests) >= cb.config.ratio {
+			cb.transition(CircuitOpen)
+		}
+	}
+}
+
+// release gives up an allowed request without recording an outcome, so a
+// half-open breaker allows another probe.
+func (cb *circuitBreaker) release() {
+	if cb.config.ratio <= 0 {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.unlock()
+	cb.probing = false
+}
+
+// transition moves the breaker to state, it has to be called with mu held.
+func (cb *circuitBreaker) transition(state CircuitState) {
+	cb.changes = append(cb.changes, cb.state, state)
+	cb.state = state
+	cb.requests, cb.failures, cb.since = 0, 0, time.Now()
+}
+
+// unlock releases mu and then reports the state changes made while it was
+// held, so the callback may use the client.
+func (cb *circuitBreaker) unlock() {
+	changes := cb.changes
+	cb.changes = nil
+	cb.mu.Unlock()
+
+	if cb.config.onChange == nil {
+		return
+	}
+	for i := 0; i < len(changes); i += 2 {
+		cb.config.onChange(cb.address, changes[i], changes[i+1])
+	}
+}
+
+// current returns the current state of the breaker.
+func (cb *circuitBreaker) current() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
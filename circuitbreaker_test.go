@@ -0,0 +1,141 @@
+package mazzaroth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	var changes []string
+	config := circuitConfig{ratio: 0.5, minRequests: 4, openTimeout: 20 * time.Millisecond,
+		onChange: func(address string, from, to CircuitState) {
+			mu.Lock()
+			changes = append(changes, address+":"+from.String()+"->"+to.String())
+			mu.Unlock()
+		}}
+	cb := newCircuitBreaker("node", config)
+
+	for _, failed := range []bool{true, false, true} {
+		if !cb.allow() {
+			t.Fatal("expected a closed breaker to allow requests")
+		}
+		cb.record(failed)
+	}
+	if cb.current() != CircuitClosed {
+		t.Fatal("expected the breaker to stay closed below min requests")
+	}
+	cb.allow()
+	cb.record(false)
+	if cb.current() != CircuitOpen || cb.allow() {
+		t.Fatal("expected the breaker to open at the failure ratio")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !cb.allow() {
+		t.Fatal("expected a probe after the open timeout")
+	}
+	if cb.allow() {
+		t.Fatal("expected a single probe")
+	}
+	cb.record(true)
+	if cb.current() != CircuitOpen {
+		t.Fatal("expected a failed probe to open the breaker")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	cb.allow()
+	cb.release()
+	if cb.current() != CircuitHalfOpen || !cb.allow() {
+		t.Fatal("expected a released probe to allow another one")
+	}
+	cb.record(false)
+	if cb.current() != CircuitClosed {
+		t.Fatal("expected a successful probe to close the breaker")
+	}
+
+	want := []string{
+		"node:CircuitClosed->CircuitOpen",
+		"node:CircuitOpen->CircuitHalfOpen",
+		"node:CircuitHalfOpen->CircuitOpen",
+		"node:CircuitOpen->CircuitHalfOpen",
+		"node:CircuitHalfOpen->CircuitClosed",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != len(want) {
+		t.Fatalf("unexpected state changes: %v", changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("unexpected state changes: %v", changes)
+		}
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var deadRequests int32
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&deadRequests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer dead.Close()
+	alive := httptest.NewServer(&xdrNode{})
+	defer alive.Close()
+
+	client, err := NewMazzarothClient(WithAddresses(dead.URL, alive.URL), WithCircuitBreaker(0.5, 2, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	failures := 0
+	for i := 0; i < 10; i++ {
+		if _, err := client.ReceiptLookup(ctx, "ab", "01"); err != nil {
+			failures++
+		}
+	}
+	if failures != 2 || deadRequests != 2 {
+		t.Fatalf("expected the dead node to be skipped after 2 failures, got %d failures and %d requests", failures, deadRequests)
+	}
+
+	client, err = NewMazzarothClient(WithAddress(dead.URL), WithCircuitBreaker(0.5, 2, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.ReceiptLookup(ctx, "ab", "01")
+	client.ReceiptLookup(ctx, "ab", "01")
+	if _, err := client.ReceiptLookup(ctx, "ab", "01"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the circuit to be open, got: %v", err)
+	}
+
+	// cancelled requests and health probes are not recorded
+	client, err = NewMazzarothClient(WithAddress(dead.URL), WithCircuitBreaker(0.5, 2, time.Minute), WithHealthCheck("ab", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	client.ReceiptLookup(cancelled, "ab", "01")
+	client.Health(ctx)
+	client.Health(ctx)
+	client.ReceiptLookup(ctx, "ab", "01")
+	if state := client.nodes.nodes[0].breaker.current(); state != CircuitClosed {
+		t.Fatalf("expected a single recorded request, got: %v", state)
+	}
+	client.ReceiptLookup(ctx, "ab", "01")
+	if state := client.nodes.nodes[0].breaker.current(); state != CircuitOpen {
+		t.Fatalf("expected the circuit to open, got: %v", state)
+	}
+
+	if _, err := NewMazzarothClient(WithAddresses()); err != ErrEmptyServerList {
+		t.Fatalf("expected an empty server list error, got: %v", err)
+	}
+}
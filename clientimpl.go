@@ -4,10 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
 	"github.com/pkg/errors"
@@ -19,8 +19,17 @@ var _ Client = &ClientImpl{}
 
 // ClientImpl is the actual client implementation.
 type ClientImpl struct {
-	httpClient *http.Client
-	address    string
+	httpClient   *http.Client
+	nodes        *serverSelector
+	interceptors []Interceptor
+	telemetry    *telemetry
+	logger       Logger
+	limits       *rateLimits
+	hedgeDelay   time.Duration
+	health       healthConfig
+	auth         authOptions
+	// session is set for clients returned by Session.
+	session *session
 }
 
 // NewMazzarothClient creates a production object.
@@ -32,17 +41,39 @@ func NewMazzarothClient(options ...Options) (*ClientImpl, error) {
 		opt.apply(clientOptions)
 	}
 
+	httpClient, err := clientOptions.tls.withTLS(clientOptions.httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := newServerSelector(clientOptions.addresses, clientOptions.circuit, clientOptions.encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	telemetry, err := newTelemetry(clientOptions.tracerProvider, clientOptions.meterProvider)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ClientImpl{
-		httpClient: clientOptions.httpClient,
-		address:    clientOptions.address,
+		telemetry:    telemetry,
+		logger:       loggerOrNop(clientOptions.logger),
+		limits:       newRateLimits(clientOptions.readLimit, clientOptions.submitLimit),
+		httpClient:   httpClient,
+		nodes:        nodes,
+		hedgeDelay:   clientOptions.hedgeDelay,
+		health:       clientOptions.health,
+		auth:         clientOptions.auth,
+		interceptors: clientOptions.interceptors,
 	}, nil
 }
 
 // BlockHeight calls the endpoint: /v1/channels/{channel_id}/blocks/height.
 func (c *ClientImpl) BlockHeight(ctx context.Context, channelID string) (*xdr.BlockHeight, error) {
-	url := fmt.Sprintf("%s/%s/channels/%s/blocks/height", c.address, version, channelID)
+	path := fmt.Sprintf("/%s/channels/%s/blocks/height", version, channelID)
 
-	xdrResp, err := c.do(ctx, url, http.MethodGet, nil)
+	xdrResp, err := c.do(ctx, RequestInfo{Endpoint: "BlockHeight", ChannelID: channelID}, path, http.MethodGet, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to handle http response")
 	}
@@ -56,9 +87,9 @@ func (c *ClientImpl) BlockHeight(ctx context.Context, channelID string) (*xdr.Bl
 
 // BlockLookup calls the endpoint: /v1/channels/{channel_id}/blocks/{id}.
 func (c *ClientImpl) BlockLookup(ctx context.Context, channelID, blockID string) (*xdr.Block, error) {
-	url := fmt.Sprintf("%s/%s/channels/%s/blocks/%s", c.address, version, channelID, blockID)
+	path := fmt.Sprintf("/%s/channels/%s/blocks/%s", version, channelID, blockID)
 
-	xdrResp, err := c.do(ctx, url, http.MethodGet, nil)
+	xdrResp, err := c.do(ctx, RequestInfo{Endpoint: "BlockLookup", ChannelID: channelID, BlockID: blockID}, path, http.MethodGet, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to handle http response")
 	}
@@ -72,9 +103,9 @@ func (c *ClientImpl) BlockLookup(ctx context.Context, channelID, blockID string)
 
 // BlockList calls the endpoint: /v1/channels/{channel_id}/blocks?{number,height}.
 func (c *ClientImpl) BlockList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.Block, error) {
-	url := fmt.Sprintf("%s/%s/channels/%s/blocks?height=%d&number=%d", c.address, version, channelID, blockHeight, number)
+	path := fmt.Sprintf("/%s/channels/%s/blocks?height=%d&number=%d", version, channelID, blockHeight, number)
 
-	xdrResp, err := c.do(ctx, url, http.MethodGet, nil)
+	xdrResp, err := c.do(ctx, RequestInfo{Endpoint: "BlockList", ChannelID: channelID, BlockHeight: blockHeight}, path, http.MethodGet, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to handle http response")
 	}
@@ -88,9 +119,9 @@ func (c *ClientImpl) BlockList(ctx context.Context, channelID string, blockHeigh
 
 // BlockHeaderLookup calls the endpoint: /v1/channels/{channel_id}/blockheaders/{id}.
 func (c *ClientImpl) BlockHeaderLookup(ctx context.Context, channelID, blockID string) (*xdr.BlockHeader, error) {
-	url := fmt.Sprintf("%s/%s/channels/%s/blockheaders/%s", c.address, version, channelID, blockID)
+	path := fmt.Sprintf("/%s/channels/%s/blockheaders/%s", version, channelID, blockID)
 
-	xdrResp, err := c.do(ctx, url, http.MethodGet, nil)
+	xdrResp, err := c.do(ctx, RequestInfo{Endpoint: "BlockHeaderLookup", ChannelID: channelID, BlockID: blockID}, path, http.MethodGet, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to handle http response")
 	}
@@ -104,9 +135,9 @@ func (c *ClientImpl) BlockHeaderLookup(ctx context.Context, channelID, blockID s
 
 // BlockHeaderList calls the endpoint: /v1/channels/{channel_id}/blockheaders?{blockHeight,number}.
 func (c *ClientImpl) BlockHeaderList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.BlockHeader, error) {
-	url := fmt.Sprintf("%s/%s/channels/%s/blockheaders?height=%d&number=%d", c.address, version, channelID, blockHeight, number)
+	path := fmt.Sprintf("/%s/channels/%s/blockheaders?height=%d&number=%d", version, channelID, blockHeight, number)
 
-	xdrResp, err := c.do(ctx, url, http.MethodGet, nil)
+	xdrResp, err := c.do(ctx, RequestInfo{Endpoint: "BlockHeaderList", ChannelID: channelID, BlockHeight: blockHeight}, path, http.MethodGet, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to handle http response")
 	}
@@ -120,9 +151,9 @@ func (c *ClientImpl) BlockHeaderList(ctx context.Context, channelID string, bloc
 
 // ChannelAbi calls the endpoint: /v1/channels/{channel_id}/abi.
 func (c *ClientImpl) ChannelAbi(ctx context.Context, channelID string) (*xdr.Abi, error) {
-	url := fmt.Sprintf("%s/%s/channels/%s/abi", c.address, version, channelID)
+	path := fmt.Sprintf("/%s/channels/%s/abi", version, channelID)
 
-	xdrResp, err := c.do(ctx, url, http.MethodGet, nil)
+	xdrResp, err := c.do(ctx, RequestInfo{Endpoint: "ChannelAbi", ChannelID: channelID}, path, http.MethodGet, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to handle http response")
 	}
@@ -136,9 +167,9 @@ func (c *ClientImpl) ChannelAbi(ctx context.Context, channelID string) (*xdr.Abi
 
 // ReceiptLookup calls the endpoint: /v1/channels/{channel_id}/receipts/{id}.
 func (c *ClientImpl) ReceiptLookup(ctx context.Context, channelID, transactionID string) (*xdr.Receipt, error) {
-	url := fmt.Sprintf("%s/%s/channels/%s/receipts/%s", c.address, version, channelID, transactionID)
+	path := fmt.Sprintf("/%s/channels/%s/receipts/%s", version, channelID, transactionID)
 
-	xdrResp, err := c.do(ctx, url, http.MethodGet, nil)
+	xdrResp, err := c.do(ctx, RequestInfo{Endpoint: "ReceiptLookup", ChannelID: channelID, TransactionID: transactionID}, path, http.MethodGet, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to handle http response")
 	}
@@ -152,19 +183,11 @@ func (c *ClientImpl) ReceiptLookup(ctx context.Context, channelID, transactionID
 
 // TransactionSubmit calls the endpoint: /v1/channels/{channel_id}/transactions.
 func (c *ClientImpl) TransactionSubmit(ctx context.Context, transaction *xdr.Transaction) (*xdr.ID, *xdr.Receipt, error) {
-	b, err := json.Marshal(transaction)
-	if err != nil {
-		return nil, nil, errors.Wrap(err, "unable to marshal to json")
-	}
-
 	channelID := hex.EncodeToString(transaction.Data.ChannelID[:])
-	if err != nil {
-		return nil, nil, errors.Wrap(err, "unable to unmarshal the channelID")
-	}
 
-	url := fmt.Sprintf("%s/%s/channels/%s/transactions", c.address, version, channelID)
+	path := fmt.Sprintf("/%s/channels/%s/transactions", version, channelID)
 
-	xdrResp, err := c.do(ctx, url, http.MethodPost, b)
+	xdrResp, err := c.do(ctx, RequestInfo{Endpoint: "TransactionSubmit", ChannelID: channelID}, path, http.MethodPost, transaction)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "unable to make a request to transaction submit endpoint")
 	}
@@ -179,9 +202,9 @@ func (c *ClientImpl) TransactionSubmit(ctx context.Context, transaction *xdr.Tra
 
 // TransactionLookup calls the endpoint: /v1/channels/{channel_id}/transactions/{id}.
 func (c *ClientImpl) TransactionLookup(ctx context.Context, channelID string, transactionID string) (*xdr.Transaction, error) {
-	url := fmt.Sprintf("%s/%s/channels/%s/transactions/%s", c.address, version, channelID, transactionID)
+	path := fmt.Sprintf("/%s/channels/%s/transactions/%s", version, channelID, transactionID)
 
-	xdrResp, err := c.do(ctx, url, http.MethodGet, nil)
+	xdrResp, err := c.do(ctx, RequestInfo{Endpoint: "TransactionLookup", ChannelID: channelID, TransactionID: transactionID}, path, http.MethodGet, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to handle http response")
 	}
@@ -193,31 +216,238 @@ func (c *ClientImpl) TransactionLookup(ctx context.Context, channelID string, tr
 	return nil, errors.New("missing transaction")
 }
 
-func (c *ClientImpl) do(ctx context.Context, url string, method string, body []byte) (*xdr.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to create a new request")
+// do makes a request to the next node the circuit breakers allow.
+func (c *ClientImpl) do(ctx context.Context, info RequestInfo, path string, method string, body interface{}) (*xdr.Response, error) {
+	ctx, finish := c.telemetry.start(ctx, info, method)
+	read := info.Endpoint != "TransactionSubmit"
+
+	var (
+		node         *node
+		xdrResp      *xdr.Response
+		status, size int
+		err          error
+	)
+	switch {
+	case c.session != nil && read:
+		node, xdrResp, status, size, err = c.sessionRead(ctx, info, path, method, body)
+	case c.hedgeDelay > 0 && read && len(c.nodes.nodes) > 1:
+		if node, err = c.nodes.selectNode(read); err == nil {
+			node, xdrResp, status, size, err = c.hedge(ctx, node, info, path, method, body)
+		}
+	default:
+		if node, err = c.nodes.selectNode(read); err == nil {
+			xdrResp, status, size, err = c.doNode(ctx, node, info, path, method, body)
+		}
+	}
+	if node == nil {
+		finish("", nil, 0, 0, err)
+		return nil, err
+	}
+
+	if c.session != nil && err == nil {
+		c.sessionObserve(ctx, node, info, xdrResp)
+	}
+	finish(node.address, xdrResp, status, size, err)
+	return xdrResp, err
+}
+
+// hedgeResult is the outcome of a hedged request.
+type hedgeResult struct {
+	node    *node
+	xdrResp *xdr.Response
+	status  int
+	size    int
+	err     error
+}
+
+// hedge makes a read request to first and, if it did not answer after the
+// hedge delay, the same request to a second node. The first successful
+// response is returned and the other request cancelled. A first node
+// cancelled that way is recorded as failed by its circuit breaker, a
+// cancelled second node is not recorded.
+func (c *ClientImpl) hedge(ctx context.Context, first *node, info RequestInfo, path string, method string, body interface{}) (*node, *xdr.Response, int, int, error) {
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	launch := func(n *node) {
+		go func() {
+			xdrResp, status, size, err := c.probeNode(hedgeCtx, n, info, path, method, body)
+			cancelled := err != nil && hedgeCtx.Err() != nil
+			switch {
+			case cancelled && (ctx.Err() != nil || n != first):
+				n.breaker.release()
+			case cancelled:
+				// the node was outpaced by one asked a hedge delay later
+				n.breaker.record(true)
+			default:
+				n.breaker.record(requestFailed(status, err))
+			}
+			results <- hedgeResult{node: n, xdrResp: xdrResp, status: status, size: size, err: err}
+		}()
+	}
+	launch(first)
+	pending := 1
+
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			second, err := c.nodes.selectOther(first, true)
+			if err != nil {
+				continue
+			}
+			c.logger.Debug("hedging request", "endpoint", info.Endpoint, "address", second.address, "slow", first.address)
+			launch(second)
+			pending++
+		case result := <-results:
+			pending--
+			if result.err == nil || pending == 0 {
+				return result.node, result.xdrResp, result.status, result.size, result.err
+			}
+		}
+	}
+}
+
+// doNode makes a request its circuit breaker allowed to node and records
+// the outcome in the breaker.
+func (c *ClientImpl) doNode(ctx context.Context, node *node, info RequestInfo, path string, method string, body interface{}) (*xdr.Response, int, int, error) {
+	xdrResp, status, size, err := c.probeNode(ctx, node, info, path, method, body)
+	if ctx.Err() != nil {
+		// requests given up by the caller say nothing about the node
+		node.breaker.release()
+	} else {
+		node.breaker.record(requestFailed(status, err))
 	}
+	return xdrResp, status, size, err
+}
+
+// refusesXDR returns whether a response status to an xdr encoded request
+// means the node does not speak xdr. A bad request is not a refusal, nodes
+// answer invalid transactions with it.
+func refusesXDR(status int) bool {
+	return status == http.StatusNotAcceptable || status == http.StatusUnsupportedMediaType
+}
+
+// requestFailed returns whether a request failed for a reason the circuit
+// breaker of its node counts: no response or a server error.
+func requestFailed(status int, err error) bool {
+	return err != nil && (status == 0 || status >= http.StatusInternalServerError)
+}
+
+// probeNode makes a request to node, falling back to json and backing off as
+// the node asks. The circuit breaker of node is neither consulted nor fed.
+func (c *ClientImpl) probeNode(ctx context.Context, node *node, info RequestInfo, path string, method string, body interface{}) (*xdr.Response, int, int, error) {
+	info.Address = node.address
+	url := node.address + path
+	budget := c.limits.budget(node.address, info.Endpoint == "TransactionSubmit")
+
+	var (
+		xdrResp      *xdr.Response
+		status, size int
+		err          error
+		retries      int
+	)
+	encoding := node.currentEncoding()
+	for attempt := 1; ; attempt++ {
+		release, waitErr := budget.acquire(ctx)
+		if waitErr != nil {
+			err = errors.Wrap(waitErr, "unable to wait for the rate limit")
+			break
+		}
+		xdrResp, status, size, err = c.request(ctx, info, url, method, body, encoding, attempt)
+		release()
+
+		if encoding == EncodingXDR && refusesXDR(status) {
+			// the node does not speak xdr, stay with json for it from now on
+			node.setEncoding(EncodingJSON)
+			c.logger.Info("node refused xdr encoding, falling back to json", "address", node.address, "status", status)
+			encoding = EncodingJSON
+			continue
+		}
+
+		var statusErr *statusError
+		if status == http.StatusTooManyRequests && retries < maxRateLimitRetries && errors.As(err, &statusErr) {
+			backoff := retryAfter(statusErr.header, retries)
+			budget.backoff(backoff)
+			c.logger.Info("node is rate limiting requests, backing off", "address", node.address, "backoff", backoff)
+			retries++
+			continue
+		}
+		break
+	}
+	return xdrResp, status, size, err
+}
+
+// statusError is returned for responses with a status other than
+// http.StatusOK.
+type statusError struct {
+	code   int
+	header http.Header
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("request failed with status %d", e.code)
+}
 
-	response, err := c.httpClient.Do(req)
+// request makes a single http request using encoding and returns the decoded
+// response along with the http status code and the size of the response body.
+// The request is logged as the given attempt of the call.
+func (c *ClientImpl) request(ctx context.Context, info RequestInfo, url string, method string, body interface{}, encoding Encoding, attempt int) (*xdr.Response, int, int, error) {
+	begin := time.Now()
+	xdrResp, status, size, err := c.send(ctx, info, url, method, body, encoding)
+
+	args := []interface{}{"endpoint", info.Endpoint, "method", method, "url", url, "status", status,
+		"duration", time.Since(begin), "bytes", size, "attempt", attempt}
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to make http request")
+		c.logger.Warn("mazzaroth request failed", append(args, "error", err)...)
+	} else {
+		c.logger.Debug("mazzaroth request", args...)
 	}
+	return xdrResp, status, size, err
+}
 
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("request failed with status %d", response.StatusCode)
+// send makes the http request for request.
+func (c *ClientImpl) send(ctx context.Context, info RequestInfo, url string, method string, body interface{}, encoding Encoding) (*xdr.Response, int, int, error) {
+	var b []byte
+	if body != nil {
+		var err error
+		b, err = encodeBody(encoding, body)
+		if err != nil {
+			return nil, 0, 0, err
+		}
 	}
 
-	responseBody, err := io.ReadAll(response.Body)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(b))
 	if err != nil {
-		return nil, errors.Wrap(err, "could not read the body")
+		return nil, 0, 0, errors.Wrap(err, "unable to create a new request")
+	}
+	req.Header.Set("Accept", encoding.contentType())
+	if body != nil {
+		req.Header.Set("Content-Type", encoding.contentType())
+	}
+	c.telemetry.inject(ctx, req.Header)
+	if err := c.auth.apply(ctx, req, b); err != nil {
+		return nil, 0, 0, errors.Wrap(err, "unable to authenticate the request")
 	}
 
-	xdrResp := xdr.Response{}
-	err = xdrResp.UnmarshalJSON(responseBody)
+	response, err := chainInterceptors(info, c.interceptors, c.httpClient.Do)(req)
+	if err != nil {
+		return nil, 0, 0, errors.Wrap(err, "unable to make http request")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, response.StatusCode, 0, &statusError{code: response.StatusCode, header: response.Header}
+	}
+
+	responseBody, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not unmarshal the body")
+		return nil, response.StatusCode, len(responseBody), errors.Wrap(err, "could not read the body")
 	}
 
-	return &xdrResp, nil
+	xdrResp, err := decodeResponse(response.Header.Get("Content-Type"), responseBody)
+	return xdrResp, response.StatusCode, len(responseBody), err
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kochavalabs/mazzaroth-go"
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+func describeCommand(args []string) error {
+	flags := flag.NewFlagSet("describe", flag.ExitOnError)
+	abiFile := flags.String("abi", "", "json abi file used to label call arguments")
+	asJSON := flags.Bool("json", false, "print the description as json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: mazzaroth describe [flags] [transaction.json]\n\nreads the transaction from stdin if no file is given")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	var b []byte
+	var err error
+	if flags.NArg() > 0 {
+		b, err = os.ReadFile(flags.Arg(0))
+	} else {
+		b, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to read transaction")
+	}
+	tx := &xdr.Transaction{}
+	if err := json.Unmarshal(b, tx); err != nil {
+		return errors.Wrap(err, "unable to decode transaction")
+	}
+
+	var abi *xdr.Abi
+	if *abiFile != "" {
+		b, err := os.ReadFile(*abiFile)
+		if err != nil {
+			return errors.Wrap(err, "unable to read abi")
+		}
+		abi = &xdr.Abi{}
+		if err := json.Unmarshal(b, abi); err != nil {
+			return errors.Wrap(err, "unable to decode abi")
+		}
+	}
+
+	description := mazzaroth.Describe(tx, abi)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(description)
+	}
+	fmt.Print(description)
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-go"
+	"github.com/kochavalabs/mazzaroth-go/export"
+	"github.com/pkg/errors"
+)
+
+func exportCommand(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	address := flags.String("address", "http://localhost:6299", "address of the readonly node")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout of a single request")
+	channelID := flags.String("channel", "", "hex encoded id of the channel to export")
+	from := flags.Uint64("from", 0, "first block height to export")
+	to := flags.Uint64("to", 0, "last block height to export, defaults to the current height")
+	format := flags.String("format", string(export.FormatJSONLines), "output format, jsonl or csv")
+	out := flags.String("out", ".", "output directory, holding the checkpoint of a resumable export")
+	skipReceipts := flags.Bool("skip-receipts", false, "do not export receipts")
+	flags.Parse(args)
+
+	if *channelID == "" {
+		return errors.New("missing -channel")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := mazzaroth.NewMazzarothClient(
+		mazzaroth.WithAddress(*address),
+		mazzaroth.WithHttpClient(&http.Client{Timeout: *timeout}),
+	)
+	if err != nil {
+		return err
+	}
+
+	if *to == 0 {
+		height, err := client.BlockHeight(ctx, *channelID)
+		if err != nil {
+			return errors.Wrap(err, "unable to get block height")
+		}
+		*to = height.Height
+	}
+
+	exporter, err := export.New(client, *channelID, *out, export.Format(*format))
+	if err != nil {
+		return err
+	}
+	if *skipReceipts {
+		exporter.SkipReceipts()
+	}
+
+	height, err := exporter.Export(ctx, *from, *to)
+	if err != nil {
+		return errors.Wrapf(err, "export stopped after height %d", height)
+	}
+	fmt.Printf("exported channel %s up to height %d\n", *channelID, height)
+	return nil
+}
@@ -0,0 +1,44 @@
+// Command mazzaroth is a command line client for mazzaroth readonly nodes.
+//
+// Usage:
+//
+//	mazzaroth <command> [flags]
+//
+// Run a command with -h for its flags.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// commands maps the command names to their implementations, each taking the
+// arguments following the command name.
+var commands = map[string]func(args []string) error{
+	"describe": describeCommand,
+	"export":   exportCommand,
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: mazzaroth <command> [flags]
+
+commands:
+  describe  print a json transaction in human readable form
+  export    export blocks, transactions and receipts of a channel`)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := command(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "mazzaroth:", err)
+		os.Exit(1)
+	}
+}
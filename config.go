@@ -0,0 +1,361 @@
+package mazzaroth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by LoadConfig. MAZZAROTH_CONFIG and
+// MAZZAROTH_PROFILE select the file and profile, the others override the
+// field of the same name, lists being comma separated with blanks ignored.
+const (
+	envPrefix  = "MAZZAROTH_"
+	envConfig  = envPrefix + "CONFIG"
+	envProfile = envPrefix + "PROFILE"
+)
+
+// Duration is a time.Duration written as a string like "500ms" in config
+// files.
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return errors.New("durations have to be strings like \"500ms\"")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return errors.Wrap(err, "invalid duration")
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// TLSConfig is the tls section of a Config.
+type TLSConfig struct {
+	CertFile   string   `json:"certFile"`
+	KeyFile    string   `json:"keyFile"`
+	CAFiles    []string `json:"caFiles"`
+	ServerName string   `json:"serverName"`
+	// MinVersion is "1.2" or "1.3".
+	MinVersion string `json:"minVersion"`
+}
+
+// AuthConfig is the auth section of a Config.
+type AuthConfig struct {
+	BearerToken    string `json:"bearerToken"`
+	APIKeyHeader   string `json:"apiKeyHeader"`
+	APIKey         string `json:"apiKey"`
+	SigningKeyPath string `json:"signingKeyPath"`
+}
+
+// ResilienceConfig is the resilience section of a Config, covering how the
+// client rate limits its requests, hedges slow reads and stops sending to
+// failing nodes.
+type ResilienceConfig struct {
+	RateLimit          float64  `json:"rateLimit"`
+	Burst              int      `json:"burst"`
+	MaxInFlight        int      `json:"maxInFlight"`
+	HedgeDelay         Duration `json:"hedgeDelay"`
+	CircuitRatio       float64  `json:"circuitRatio"`
+	CircuitMinRequests int      `json:"circuitMinRequests"`
+	CircuitOpenTimeout Duration `json:"circuitOpenTimeout"`
+}
+
+// Config configures a client for an environment. It is read from a YAML,
+// JSON or TOML file whose top level fields apply to every profile and whose
+// profiles section holds named profiles like dev, staging or prod
+// overriding them:
+//
+//	addresses: ["http://localhost:6299"]
+//	timeout: 500ms
+//	profiles:
+//	  prod:
+//	    addresses: ["https://node-1.example.com", "https://node-2.example.com"]
+//	    timeout: 2s
+//	    tls:
+//	      caFiles: ["/etc/mazzaroth/ca.pem"]
+type Config struct {
+	Addresses []string `json:"addresses"`
+	Timeout   Duration `json:"timeout"`
+	// Encoding is "json" or "xdr".
+	Encoding   string           `json:"encoding"`
+	TLS        TLSConfig        `json:"tls"`
+	Auth       AuthConfig       `json:"auth"`
+	Resilience ResilienceConfig `json:"resilience"`
+	// Channel is the hex encoded channel used when none is given.
+	Channel string `json:"channel"`
+	// SenderKeyPath is a file holding the hex encoded ed25519 seed of the
+	// account transactions are sent from by default.
+	SenderKeyPath string `json:"senderKeyPath"`
+}
+
+// LoadConfig reads the profile of the config file at path and applies the
+// MAZZAROTH_* environment variables on top. An empty path falls back to
+// MAZZAROTH_CONFIG, and no file is read if that is empty as well. An empty
+// profile falls back to MAZZAROTH_PROFILE and then to the profile field of
+// the file, only the top level fields apply if none is set.
+func LoadConfig(path, profile string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv(envConfig)
+	}
+	if profile == "" {
+		profile = os.Getenv(envProfile)
+	}
+
+	config := &Config{}
+	if path != "" {
+		if err := config.load(path, profile); err != nil {
+			return nil, err
+		}
+	} else if profile != "" {
+		return nil, errors.Errorf("profile %s given without a config file", profile)
+	}
+
+	if err := config.loadEnv(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// load reads path into c.
+func (c *Config) load(path, profile string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "unable to read config file")
+	}
+
+	fields := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &fields)
+	case ".json":
+		err = json.Unmarshal(b, &fields)
+	case ".toml":
+		err = toml.Unmarshal(b, &fields)
+	default:
+		return ErrUnknownConfigFormat
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to parse config file")
+	}
+
+	profiles, _ := fields["profiles"].(map[string]interface{})
+	if profile == "" {
+		profile, _ = fields["profile"].(string)
+	}
+	delete(fields, "profiles")
+	delete(fields, "profile")
+
+	if err := decodeFields(fields, c); err != nil {
+		return err
+	}
+	if profile == "" {
+		return nil
+	}
+	overrides, ok := profiles[profile]
+	if !ok {
+		return errors.Errorf("profile %s not found in %s", profile, path)
+	}
+	return decodeFields(overrides, c)
+}
+
+// decodeFields decodes the fields parsed from any of the config formats
+// into c, overriding the fields of c that are set.
+func decodeFields(fields interface{}, c *Config) error {
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return errors.Wrap(err, "unable to read config fields")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	return errors.Wrap(decoder.Decode(c), "invalid config")
+}
+
+// loadEnv overrides the fields of c set by environment variables.
+func (c *Config) loadEnv() error {
+	str := func(name string, field *string) {
+		if v, ok := os.LookupEnv(envPrefix + name); ok {
+			*field = v
+		}
+	}
+	list := func(name string, field *[]string) {
+		if v, ok := os.LookupEnv(envPrefix + name); ok {
+			*field = nil
+			for _, e := range strings.Split(v, ",") {
+				if e = strings.TrimSpace(e); e != "" {
+					*field = append(*field, e)
+				}
+			}
+		}
+	}
+	var err error
+	duration := func(name string, field *Duration) {
+		if v, ok := os.LookupEnv(envPrefix + name); ok && err == nil {
+			var d time.Duration
+			d, err = time.ParseDuration(v)
+			err = errors.Wrap(err, envPrefix+name)
+			*field = Duration(d)
+		}
+	}
+	integer := func(name string, field *int) {
+		if v, ok := os.LookupEnv(envPrefix + name); ok && err == nil {
+			*field, err = strconv.Atoi(v)
+			err = errors.Wrap(err, envPrefix+name)
+		}
+	}
+	float := func(name string, field *float64) {
+		if v, ok := os.LookupEnv(envPrefix + name); ok && err == nil {
+			*field, err = strconv.ParseFloat(v, 64)
+			err = errors.Wrap(err, envPrefix+name)
+		}
+	}
+
+	list("ADDRESSES", &c.Addresses)
+	duration("TIMEOUT", &c.Timeout)
+	str("ENCODING", &c.Encoding)
+	str("CHANNEL", &c.Channel)
+	str("SENDER_KEY_PATH", &c.SenderKeyPath)
+	str("TLS_CERT_FILE", &c.TLS.CertFile)
+	str("TLS_KEY_FILE", &c.TLS.KeyFile)
+	list("TLS_CA_FILES", &c.TLS.CAFiles)
+	str("TLS_SERVER_NAME", &c.TLS.ServerName)
+	str("TLS_MIN_VERSION", &c.TLS.MinVersion)
+	str("BEARER_TOKEN", &c.Auth.BearerToken)
+	str("API_KEY_HEADER", &c.Auth.APIKeyHeader)
+	str("API_KEY", &c.Auth.APIKey)
+	str("SIGNING_KEY_PATH", &c.Auth.SigningKeyPath)
+	float("RATE_LIMIT", &c.Resilience.RateLimit)
+	integer("BURST", &c.Resilience.Burst)
+	integer("MAX_IN_FLIGHT", &c.Resilience.MaxInFlight)
+	duration("HEDGE_DELAY", &c.Resilience.HedgeDelay)
+	float("CIRCUIT_RATIO", &c.Resilience.CircuitRatio)
+	integer("CIRCUIT_MIN_REQUESTS", &c.Resilience.CircuitMinRequests)
+	duration("CIRCUIT_OPEN_TIMEOUT", &c.Resilience.CircuitOpenTimeout)
+	return err
+}
+
+// Options returns the client options of the config.
+func (c *Config) Options() ([]Options, error) {
+	var options []Options
+	if len(c.Addresses) > 0 {
+		options = append(options, WithAddresses(c.Addresses...))
+	}
+	if c.Timeout > 0 {
+		options = append(options, WithHttpClient(&http.Client{Timeout: time.Duration(c.Timeout)}))
+	}
+
+	switch c.Encoding {
+	case "", "json":
+	case "xdr":
+		options = append(options, WithEncoding(EncodingXDR))
+	default:
+		return nil, errors.Errorf("unknown encoding %s", c.Encoding)
+	}
+
+	if c.TLS.CertFile != "" || c.TLS.KeyFile != "" {
+		options = append(options, WithClientCertificateFiles(c.TLS.CertFile, c.TLS.KeyFile))
+	}
+	if len(c.TLS.CAFiles) > 0 {
+		options = append(options, WithRootCAFiles(c.TLS.CAFiles...))
+	}
+	if c.TLS.ServerName != "" {
+		options = append(options, WithServerName(c.TLS.ServerName))
+	}
+	switch c.TLS.MinVersion {
+	case "":
+	case "1.2":
+		options = append(options, WithMinTLSVersion(tls.VersionTLS12))
+	case "1.3":
+		options = append(options, WithMinTLSVersion(tls.VersionTLS13))
+	default:
+		return nil, errors.Errorf("unsupported tls version %s", c.TLS.MinVersion)
+	}
+
+	if c.Auth.BearerToken != "" {
+		options = append(options, WithBearerToken(c.Auth.BearerToken))
+	}
+	if c.Auth.APIKey != "" {
+		header := c.Auth.APIKeyHeader
+		if header == "" {
+			header = "X-Api-Key"
+		}
+		options = append(options, WithAPIKey(header, c.Auth.APIKey))
+	}
+	if c.Auth.SigningKeyPath != "" {
+		key, err := readKey(c.Auth.SigningKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithRequestSigning(key))
+	}
+
+	if c.Resilience.RateLimit > 0 {
+		options = append(options, WithRateLimit(c.Resilience.RateLimit, c.Resilience.Burst))
+	}
+	if c.Resilience.MaxInFlight > 0 {
+		options = append(options, WithMaxInFlight(c.Resilience.MaxInFlight))
+	}
+	if c.Resilience.HedgeDelay > 0 {
+		options = append(options, WithHedging(time.Duration(c.Resilience.HedgeDelay)))
+	}
+	if c.Resilience.CircuitRatio > 0 {
+		options = append(options, WithCircuitBreaker(c.Resilience.CircuitRatio, c.Resilience.CircuitMinRequests, time.Duration(c.Resilience.CircuitOpenTimeout)))
+	}
+	return options, nil
+}
+
+// SenderKey reads the key of the default sender.
+func (c *Config) SenderKey() (ed25519.PrivateKey, error) {
+	if c.SenderKeyPath == "" {
+		return nil, errors.New("no sender key path configured")
+	}
+	return readKey(c.SenderKeyPath)
+}
+
+// readKey reads a file holding a hex encoded ed25519 seed.
+func readKey(path string) (ed25519.PrivateKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read key")
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.Errorf("%s does not hold a hex encoded %d byte seed", path, ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// NewMazzarothClientFromConfig creates a client from the profile of a
+// config file and the environment as read by LoadConfig. Options given are
+// applied after those of the config. The config is returned for its default
+// channel and sender.
+func NewMazzarothClientFromConfig(path, profile string, options ...Options) (*ClientImpl, *Config, error) {
+	config, err := LoadConfig(path, profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	configOptions, err := config.Options()
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := NewMazzarothClient(append(configOptions, options...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, config, nil
+}
@@ -0,0 +1,173 @@
+package mazzaroth
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var configFiles = map[string]string{
+	"config.yaml": `
+addresses: ["http://localhost:6299"]
+timeout: 500ms
+channel: ab
+profile: dev
+profiles:
+  dev:
+    timeout: 1s
+  prod:
+    addresses: ["https://node-1.example.com", "https://node-2.example.com"]
+    timeout: 2s
+    encoding: xdr
+    resilience:
+      rateLimit: 10
+      burst: 5
+`,
+	"config.json": `{
+  "addresses": ["http://localhost:6299"],
+  "timeout": "500ms",
+  "channel": "ab",
+  "profile": "dev",
+  "profiles": {
+    "dev": {"timeout": "1s"},
+    "prod": {
+      "addresses": ["https://node-1.example.com", "https://node-2.example.com"],
+      "timeout": "2s",
+      "encoding": "xdr",
+      "resilience": {"rateLimit": 10, "burst": 5}
+    }
+  }
+}`,
+	"config.toml": `
+addresses = ["http://localhost:6299"]
+timeout = "500ms"
+channel = "ab"
+profile = "dev"
+
+[profiles.dev]
+timeout = "1s"
+
+[profiles.prod]
+addresses = ["https://node-1.example.com", "https://node-2.example.com"]
+timeout = "2s"
+encoding = "xdr"
+
+[profiles.prod.resilience]
+rateLimit = 10
+burst = 5
+`,
+}
+
+func writeConfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	for name, content := range configFiles {
+		path := writeConfig(t, name, content)
+
+		dev, err := LoadConfig(path, "")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		want := &Config{Addresses: []string{"http://localhost:6299"}, Timeout: Duration(time.Second), Channel: "ab"}
+		if !reflect.DeepEqual(dev, want) {
+			t.Errorf("%s: unexpected dev config: %+v", name, dev)
+		}
+
+		prod, err := LoadConfig(path, "prod")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		want = &Config{
+			Addresses:  []string{"https://node-1.example.com", "https://node-2.example.com"},
+			Timeout:    Duration(2 * time.Second),
+			Encoding:   "xdr",
+			Channel:    "ab",
+			Resilience: ResilienceConfig{RateLimit: 10, Burst: 5},
+		}
+		if !reflect.DeepEqual(prod, want) {
+			t.Errorf("%s: unexpected prod config: %+v", name, prod)
+		}
+
+		if _, err := LoadConfig(path, "staging"); err == nil {
+			t.Errorf("%s: expected a missing profile to fail", name)
+		}
+	}
+}
+
+func TestLoadConfigEnv(t *testing.T) {
+	path := writeConfig(t, "config.yaml", configFiles["config.yaml"])
+	t.Setenv("MAZZAROTH_CONFIG", path)
+	t.Setenv("MAZZAROTH_PROFILE", "prod")
+	t.Setenv("MAZZAROTH_ADDRESSES", " http://a:6299, ,http://b:6299,")
+	t.Setenv("MAZZAROTH_HEDGE_DELAY", "20ms")
+	t.Setenv("MAZZAROTH_BURST", "7")
+
+	config, err := LoadConfig("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config.Addresses, []string{"http://a:6299", "http://b:6299"}) ||
+		config.Timeout != Duration(2*time.Second) || config.Resilience.HedgeDelay != Duration(20*time.Millisecond) || config.Resilience.Burst != 7 {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+
+	t.Setenv("MAZZAROTH_BURST", "many")
+	if _, err := LoadConfig("", ""); err == nil || !strings.Contains(err.Error(), "MAZZAROTH_BURST") {
+		t.Fatalf("expected an invalid burst, got: %v", err)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	if _, err := LoadConfig(writeConfig(t, "config.ini", ""), ""); err != ErrUnknownConfigFormat {
+		t.Fatalf("expected an unknown format, got: %v", err)
+	}
+	if _, err := LoadConfig(writeConfig(t, "config.yaml", "adresses: [x]"), ""); err == nil {
+		t.Fatal("expected an unknown field to fail")
+	}
+	if _, err := LoadConfig(writeConfig(t, "config.yaml", "timeout: 5"), ""); err == nil {
+		t.Fatal("expected a duration without unit to fail")
+	}
+}
+
+func TestNewMazzarothClientFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "sender.key")
+	if err := os.WriteFile(keyPath, []byte(strings.Repeat("00", 32)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := writeConfig(t, "config.yaml", `
+addresses: ["http://a:6299", "http://b:6299"]
+timeout: 3s
+senderKeyPath: `+keyPath+`
+auth:
+  signingKeyPath: `+keyPath+`
+`)
+
+	client, config, err := NewMazzarothClientFromConfig(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.nodes.nodes) != 2 || client.httpClient.Timeout != 3*time.Second || client.auth.signingKey == nil {
+		t.Fatalf("unexpected client: %+v", client)
+	}
+	key, err := config.SenderKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.Equal(client.auth.signingKey) {
+		t.Fatal("expected the sender key to be the signing key")
+	}
+
+	if _, _, err := NewMazzarothClientFromConfig(writeConfig(t, "config.yaml", "encoding: protobuf"), ""); err == nil {
+		t.Fatal("expected an unknown encoding to fail")
+	}
+}
@@ -0,0 +1,158 @@
+package mazzaroth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-go/verify"
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+// conformanceBlocks is the number of latest blocks checked against a node.
+const conformanceBlocks = 20
+
+// conformanceNode returns a client for the node and channel set by
+// MAZZAROTH_TEST_ADDRESS and MAZZAROTH_TEST_CHANNEL, skipping the test if
+// they are not set. The verify package computes hashes and merkle roots the
+// node is expected to commit to, which can only be checked against the
+// blocks of a real node.
+func conformanceNode(t *testing.T) (*ClientImpl, string) {
+	address, channelID := os.Getenv("MAZZAROTH_TEST_ADDRESS"), os.Getenv("MAZZAROTH_TEST_CHANNEL")
+	if address == "" || channelID == "" {
+		t.Skip("MAZZAROTH_TEST_ADDRESS and MAZZAROTH_TEST_CHANNEL are not set")
+	}
+	client, err := NewMazzarothClient(WithAddress(address))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, channelID
+}
+
+// conformanceBlockList returns the latest blocks of the channel of a node.
+func conformanceBlockList(t *testing.T, client *ClientImpl, channelID string) []xdr.Block {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	height, err := client.BlockHeight(ctx, channelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := 1
+	if height.Height > conformanceBlocks {
+		from = int(height.Height) - conformanceBlocks + 1
+	}
+	blocks, err := client.BlockList(ctx, channelID, from, conformanceBlocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) == 0 {
+		t.Fatal("expected the node to return blocks")
+	}
+	return blocks
+}
+
+func TestConformanceMerkleRoots(t *testing.T) {
+	client, channelID := conformanceNode(t)
+	blocks := conformanceBlockList(t, client, channelID)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	headers := make([]xdr.BlockHeader, len(blocks))
+	for i := range blocks {
+		headers[i] = blocks[i].Header
+	}
+	if err := verify.HeaderChain(headers); err != nil {
+		t.Fatalf("header hashes do not match the node: %v", err)
+	}
+
+	for _, block := range blocks {
+		if err := verify.Block(&block); err != nil {
+			t.Fatalf("transactions root of block %d does not match the node: %v", block.Header.BlockHeight, err)
+		}
+
+		var receipts []xdr.Receipt
+		for i := range block.Transactions {
+			id, err := verify.TransactionID(&block.Transactions[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			receipt, err := client.ReceiptLookup(ctx, channelID, hex.EncodeToString(id[:]))
+			if err != nil {
+				t.Fatalf("receipt of transaction %d of block %d: %v", i, block.Header.BlockHeight, err)
+			}
+			receipts = append(receipts, *receipt)
+		}
+		root, err := verify.ReceiptsRoot(receipts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root != block.Header.TransactionsReceiptRoot {
+			t.Fatalf("receipts root of block %d does not match the node", block.Header.BlockHeight)
+		}
+	}
+}
+
+func TestConformanceTransactionID(t *testing.T) {
+	client, channelID := conformanceNode(t)
+	blocks := conformanceBlockList(t, client, channelID)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// transactions of the node are found under the id computed for them
+	for _, block := range blocks {
+		for i := range block.Transactions {
+			id, err := verify.TransactionID(&block.Transactions[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx, err := client.TransactionLookup(ctx, channelID, hex.EncodeToString(id[:]))
+			if err != nil {
+				t.Fatalf("transaction %d of block %d is not found under its id: %v", i, block.Header.BlockHeight, err)
+			}
+			if !reflect.DeepEqual(*tx, block.Transactions[i]) {
+				t.Fatalf("transaction %d of block %d differs from the one found under its id", i, block.Header.BlockHeight)
+			}
+		}
+	}
+
+	// the id the node returns for a submitted transaction, submitting a
+	// call that fails to execute from the hex encoded ed25519 seed set by
+	// MAZZAROTH_TEST_SENDER_KEY
+	seed, err := hex.DecodeString(os.Getenv("MAZZAROTH_TEST_SENDER_KEY"))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		t.Skip("MAZZAROTH_TEST_SENDER_KEY is not set to a hex encoded ed25519 seed")
+	}
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	sender, err := xdr.IDFromPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	channel, err := xdr.IDFromHexString(channelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	height, err := client.BlockHeight(ctx, channelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := Transaction(sender, channel).Call(GenerateNonce(), height.Height+100).Function("conformance").Sign(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _, err := client.TransactionSubmit(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := verify.TransactionID(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *id != want {
+		t.Fatalf("expected the node to return id %x, got: %x", want, *id)
+	}
+}
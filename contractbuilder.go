@@ -19,6 +19,7 @@ type ContractBuilder struct {
 	contractBytes         []byte
 	abi                   *xdr.Abi
 	version               string
+	logger                Logger
 }
 
 func (cb *ContractBuilder) Contract(sender, channel *xdr.ID, nonce, blockExpirationNumber uint64) *ContractBuilder {
@@ -49,7 +50,19 @@ func (cb *ContractBuilder) Pause(pause bool) *ContractBuilder {
 	return cb
 }
 
+// Logger sets the logger that signing events are logged to
+func (cb *ContractBuilder) Logger(logger Logger) *ContractBuilder {
+	cb.logger = logger
+	return cb
+}
+
 func (cb *ContractBuilder) Sign(pk ed25519.PrivateKey) (*xdr.Transaction, error) {
+	transaction, err := cb.sign(pk)
+	logSign(cb.logger, cb.sender, cb.categoryType, cb.nonce, err)
+	return transaction, err
+}
+
+func (cb *ContractBuilder) sign(pk ed25519.PrivateKey) (*xdr.Transaction, error) {
 	hasher := &crypto.Sha3_256Hasher{}
 	hash := hasher.Hash(cb.contractBytes)
 
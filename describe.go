@@ -0,0 +1,141 @@
+package mazzaroth
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+// Description is the human readable form of a transaction, rendered as text
+// by String and as json by encoding/json.
+type Description struct {
+	Sender                string               `json:"sender"`
+	Channel               string               `json:"channel"`
+	Nonce                 uint64               `json:"nonce,string"`
+	BlockExpirationNumber uint64               `json:"blockExpirationNumber,string"`
+	Category              string               `json:"category"`
+	Call                  *CallDescription     `json:"call,omitempty"`
+	Deploy                *ContractDescription `json:"deploy,omitempty"`
+	Pause                 *bool                `json:"pause,omitempty"`
+}
+
+// CallDescription describes the function called by a CALL transaction.
+type CallDescription struct {
+	Function  string                `json:"function"`
+	Arguments []ArgumentDescription `json:"arguments"`
+}
+
+// ArgumentDescription is an argument of a call, labeled with the name and
+// type of the matching abi parameter if there is one.
+type ArgumentDescription struct {
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// ContractDescription describes the contract of a DEPLOY transaction.
+type ContractDescription struct {
+	Owner     string `json:"owner"`
+	Version   string `json:"version"`
+	Size      int    `json:"size"`
+	Hash      string `json:"hash"`
+	Functions int    `json:"functions"`
+}
+
+// Describe renders a transaction into its human readable form. The
+// arguments of a call are labeled using the parameters of the called
+// function in abi, which may be nil.
+func Describe(tx *xdr.Transaction, abi *xdr.Abi) *Description {
+	category := tx.Data.Category
+	d := &Description{
+		Sender:                hex.EncodeToString(tx.Sender[:]),
+		Channel:               hex.EncodeToString(tx.Data.ChannelID[:]),
+		Nonce:                 tx.Data.Nonce,
+		BlockExpirationNumber: tx.Data.BlockExpirationNumber,
+		Category:              strings.TrimPrefix(category.Type.String(), "CategoryType"),
+	}
+
+	switch category.Type {
+	case xdr.CategoryTypeCALL:
+		if category.Call != nil {
+			d.Call = describeCall(category.Call, abi)
+		}
+	case xdr.CategoryTypeDEPLOY:
+		if contract := category.Contract; contract != nil {
+			d.Deploy = &ContractDescription{
+				Owner:     hex.EncodeToString(contract.Owner[:]),
+				Version:   contract.Version,
+				Size:      len(contract.ContractBytes),
+				Hash:      hex.EncodeToString(contract.ContractHash[:]),
+				Functions: len(contract.Abi.Functions),
+			}
+		}
+	case xdr.CategoryTypePAUSE:
+		d.Pause = category.Pause
+	}
+
+	return d
+}
+
+func describeCall(call *xdr.Call, abi *xdr.Abi) *CallDescription {
+	var parameters []xdr.Parameter
+	if abi != nil {
+		for _, function := range abi.Functions {
+			if function.FunctionName == call.Function {
+				parameters = function.Parameters
+				break
+			}
+		}
+	}
+
+	d := &CallDescription{
+		Function:  call.Function,
+		Arguments: make([]ArgumentDescription, len(call.Arguments)),
+	}
+	for i, argument := range call.Arguments {
+		d.Arguments[i].Value = string(argument)
+		if i < len(parameters) {
+			d.Arguments[i].Name = parameters[i].ParameterName
+			d.Arguments[i].Type = parameters[i].ParameterType
+		}
+	}
+	return d
+}
+
+// String renders the description as indented text.
+func (d *Description) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "sender:     %s\n", d.Sender)
+	fmt.Fprintf(&b, "channel:    %s\n", d.Channel)
+	fmt.Fprintf(&b, "nonce:      %d\n", d.Nonce)
+	fmt.Fprintf(&b, "expiration: %d\n", d.BlockExpirationNumber)
+	fmt.Fprintf(&b, "category:   %s\n", d.Category)
+
+	if d.Call != nil {
+		fmt.Fprintf(&b, "function:   %s\n", d.Call.Function)
+		fmt.Fprintf(&b, "arguments:\n")
+		for i, argument := range d.Call.Arguments {
+			name := argument.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			if argument.Type != "" {
+				name = fmt.Sprintf("%s (%s)", name, argument.Type)
+			}
+			fmt.Fprintf(&b, "  %s: %s\n", name, argument.Value)
+		}
+	}
+	if d.Deploy != nil {
+		fmt.Fprintf(&b, "owner:      %s\n", d.Deploy.Owner)
+		fmt.Fprintf(&b, "version:    %s\n", d.Deploy.Version)
+		fmt.Fprintf(&b, "size:       %d bytes\n", d.Deploy.Size)
+		fmt.Fprintf(&b, "hash:       %s\n", d.Deploy.Hash)
+		fmt.Fprintf(&b, "functions:  %d\n", d.Deploy.Functions)
+	}
+	if d.Pause != nil {
+		fmt.Fprintf(&b, "pause:      %t\n", *d.Pause)
+	}
+	return b.String()
+}
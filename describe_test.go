@@ -0,0 +1,82 @@
+package mazzaroth
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+func TestDescribeCall(t *testing.T) {
+	privateKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	sender, err := xdr.IDFromPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := Transaction(sender, xdr.ID{}).Call(7, 9).
+		Function("transfer").
+		Arguments(String("bob"), Uint64(5), Bool(true)).
+		Sign(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abi := &xdr.Abi{Functions: []xdr.FunctionSignature{{
+		FunctionType: xdr.FunctionTypeWRITE,
+		FunctionName: "transfer",
+		Parameters: []xdr.Parameter{
+			{ParameterName: "to", ParameterType: "string"},
+			{ParameterName: "amount", ParameterType: "u64"},
+		},
+	}}}
+
+	d := Describe(tx, abi)
+	if d.Category != "CALL" || d.Nonce != 7 || d.BlockExpirationNumber != 9 {
+		t.Fatalf("unexpected description: %+v", d)
+	}
+	want := []ArgumentDescription{
+		{Name: "to", Type: "string", Value: "bob"},
+		{Name: "amount", Type: "u64", Value: "5"},
+		{Value: "true"},
+	}
+	for i, argument := range d.Call.Arguments {
+		if argument != want[i] {
+			t.Fatalf("argument %d: expected: %v, got: %v", i, want[i], argument)
+		}
+	}
+
+	text := d.String()
+	for _, line := range []string{"function:   transfer", "  to (string): bob", "  amount (u64): 5", "  #2: true"} {
+		if !strings.Contains(text, line+"\n") {
+			t.Fatalf("expected %q in:\n%s", line, text)
+		}
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"nonce":"7"`) {
+		t.Fatalf("unexpected json: %s", b)
+	}
+}
+
+func TestDescribeDeploy(t *testing.T) {
+	privateKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	abi := &xdr.Abi{Functions: []xdr.FunctionSignature{{FunctionName: "a"}, {FunctionName: "b"}}}
+	tx, err := Transaction(xdr.ID{}, xdr.ID{}).Contract(1, 2).
+		Deploy(xdr.ID{1}, "1.0", abi, []byte("contract")).
+		Sign(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := Describe(tx, nil)
+	if d.Category != "DEPLOY" || d.Call != nil || d.Deploy == nil {
+		t.Fatalf("unexpected description: %+v", d)
+	}
+	if d.Deploy.Size != 8 || d.Deploy.Version != "1.0" || d.Deploy.Functions != 2 || !strings.HasPrefix(d.Deploy.Owner, "01") {
+		t.Fatalf("unexpected contract description: %+v", d.Deploy)
+	}
+}
@@ -0,0 +1,67 @@
+package mazzaroth
+
+import (
+	"encoding"
+	"encoding/json"
+	"mime"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+// Encoding is the wire format used to talk to a node.
+type Encoding int32
+
+const (
+	// EncodingJSON sends and receives the json form of the xdr types.
+	EncodingJSON Encoding = iota
+	// EncodingXDR sends and receives the xdr binary form, which is several
+	// times smaller for blocks holding contract bytes.
+	EncodingXDR
+)
+
+const (
+	contentTypeJSON = "application/json"
+	contentTypeXDR  = "application/xdr"
+)
+
+// contentType returns the media type of e.
+func (e Encoding) contentType() string {
+	if e == EncodingXDR {
+		return contentTypeXDR
+	}
+	return contentTypeJSON
+}
+
+// encodeBody encodes a request body.
+func encodeBody(e Encoding, body interface{}) ([]byte, error) {
+	if e == EncodingXDR {
+		marshaler, ok := body.(encoding.BinaryMarshaler)
+		if !ok {
+			return nil, errors.New("body has no xdr encoding")
+		}
+		b, err := marshaler.MarshalBinary()
+		return b, errors.Wrap(err, "unable to marshal to xdr")
+	}
+	b, err := json.Marshal(body)
+	return b, errors.Wrap(err, "unable to marshal to json")
+}
+
+// decodeResponse decodes a response body according to its content type.
+// Responses without a known content type are taken to be json, which is
+// what nodes unaware of the xdr encoding send.
+func decodeResponse(contentType string, body []byte) (*xdr.Response, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	xdrResp := xdr.Response{}
+	var err error
+	if mediaType == contentTypeXDR {
+		err = xdrResp.UnmarshalBinary(body)
+	} else {
+		err = xdrResp.UnmarshalJSON(body)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal the body")
+	}
+	return &xdrResp, nil
+}
@@ -0,0 +1,162 @@
+package mazzaroth
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+// xdrNode answers every request with a receipt, encoded as xdr if asked to
+// and accepted, and records the transactions submitted to it. Nodes
+// rejecting transactions answer submissions with a bad request.
+type xdrNode struct {
+	acceptXDR   bool
+	rejectTxs   bool
+	requests    int
+	xdrRequests int
+	submitted   []xdr.Transaction
+}
+
+func (n *xdrNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.requests++
+	wantXDR := r.Header.Get("Accept") == contentTypeXDR
+	if wantXDR {
+		n.xdrRequests++
+	}
+	if wantXDR && !n.acceptXDR {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		b, _ := io.ReadAll(r.Body)
+		tx := xdr.Transaction{}
+		var err error
+		if r.Header.Get("Content-Type") == contentTypeXDR {
+			err = tx.UnmarshalBinary(b)
+		} else {
+			err = json.Unmarshal(b, &tx)
+		}
+		if err != nil || n.rejectTxs {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n.submitted = append(n.submitted, tx)
+	}
+
+	receipt := xdr.Receipt{Status: xdr.StatusSUCCESS, Result: "ok"}
+	resp, _ := xdr.NewResponse(xdr.ResponseTypeRECEIPT, receipt)
+	if wantXDR {
+		b, _ := resp.MarshalBinary()
+		w.Header().Set("Content-Type", contentTypeXDR)
+		w.Write(b)
+		return
+	}
+	b, _ := resp.MarshalJSON()
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.Write(b)
+}
+
+func TestEncodingXDR(t *testing.T) {
+	node := &xdrNode{acceptXDR: true}
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	client, err := NewMazzarothClient(WithAddress(server.URL), WithEncoding(EncodingXDR))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt, err := client.ReceiptLookup(context.Background(), "00", "00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Result != "ok" {
+		t.Fatalf("unexpected receipt: %v", receipt)
+	}
+
+	tx := &xdr.Transaction{Data: xdr.Data{Nonce: 3, Category: xdr.Category{Type: xdr.CategoryTypeDELETE}}}
+	if _, _, err := client.TransactionSubmit(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	if len(node.submitted) != 1 || node.submitted[0].Data.Nonce != 3 {
+		t.Fatalf("unexpected submitted transactions: %v", node.submitted)
+	}
+}
+
+func TestEncodingFallback(t *testing.T) {
+	node := &xdrNode{}
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	client, err := NewMazzarothClient(WithAddress(server.URL), WithEncoding(EncodingXDR))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := &xdr.Transaction{Data: xdr.Data{Nonce: 3, Category: xdr.Category{Type: xdr.CategoryTypeDELETE}}}
+	if _, _, err := client.TransactionSubmit(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReceiptLookup(context.Background(), "00", "00"); err != nil {
+		t.Fatal(err)
+	}
+	// only the first request is refused before falling back to json
+	if node.requests != 3 || len(node.submitted) != 1 {
+		t.Fatalf("expected 3 requests and 1 submitted transaction, got: %d %d", node.requests, len(node.submitted))
+	}
+}
+
+func TestEncodingFallbackPerNode(t *testing.T) {
+	xdrOnly := &xdrNode{acceptXDR: true}
+	xdrServer := httptest.NewServer(xdrOnly)
+	defer xdrServer.Close()
+	jsonOnly := &xdrNode{}
+	jsonServer := httptest.NewServer(jsonOnly)
+	defer jsonServer.Close()
+
+	client, err := NewMazzarothClient(WithAddresses(xdrServer.URL, jsonServer.URL), WithEncoding(EncodingXDR))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := client.ReceiptLookup(context.Background(), "00", "00"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the json node falling back leaves the other node on xdr
+	if xdrOnly.requests != 2 || xdrOnly.xdrRequests != 2 {
+		t.Fatalf("expected 2 xdr requests to the xdr node, got %d of %d", xdrOnly.xdrRequests, xdrOnly.requests)
+	}
+	if jsonOnly.requests != 3 || jsonOnly.xdrRequests != 1 {
+		t.Fatalf("expected a single xdr request to the json node, got %d of %d", jsonOnly.xdrRequests, jsonOnly.requests)
+	}
+}
+
+func TestEncodingRejectedTransaction(t *testing.T) {
+	node := &xdrNode{acceptXDR: true, rejectTxs: true}
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	client, err := NewMazzarothClient(WithAddress(server.URL), WithEncoding(EncodingXDR))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := &xdr.Transaction{Data: xdr.Data{Nonce: 3, Category: xdr.Category{Type: xdr.CategoryTypeDELETE}}}
+	if _, _, err := client.TransactionSubmit(context.Background(), tx); err == nil {
+		t.Fatal("expected the transaction to be rejected")
+	}
+	if _, err := client.ReceiptLookup(context.Background(), "00", "00"); err != nil {
+		t.Fatal(err)
+	}
+	// a rejected transaction is neither resubmitted nor a reason to fall back
+	if node.requests != 2 || node.xdrRequests != 2 {
+		t.Fatalf("expected 2 xdr requests, got %d of %d", node.xdrRequests, node.requests)
+	}
+}
@@ -18,4 +18,23 @@ var (
 
 	// ErrInternalServer is raised after a 500 status code.
 	ErrInternalServer = errors.New("internal server error")
+	// ErrCircuitOpen is raised when the circuit breakers of all nodes are open.
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	// ErrNoHealthChannel is raised when checking the health of nodes without a channel to probe.
+	ErrNoHealthChannel = errors.New("no channel configured for health checks")
+	// ErrNoConsistentNode is raised when no node reached the height a session observed.
+	ErrNoConsistentNode = errors.New("no node reached the height observed by the session")
+	// ErrInvalidRequestSignature is raised when a signed request fails verification.
+	ErrInvalidRequestSignature = errors.New("invalid request signature")
+	// ErrUnknownConfigFormat is raised for config files without a .yaml, .yml, .json or .toml extension.
+	ErrUnknownConfigFormat = errors.New("unknown config file format")
+
+	// ErrNoTrustedHeader is raised when a light client has no trusted header to start from.
+	ErrNoTrustedHeader = errors.New("no trusted header for channel")
+	// ErrUntrustedHeader is raised when a header served by a node does not match the trusted header.
+	ErrUntrustedHeader = errors.New("header does not match the trusted header")
+	// ErrUnrequestedBlock is raised when a node returns blocks other than the requested ones.
+	ErrUnrequestedBlock = errors.New("block is not the requested one")
+	// ErrUnverifiable is raised when a result can not be verified against the trusted headers.
+	ErrUnverifiable = errors.New("result can not be verified against trusted headers")
 )
@@ -0,0 +1,323 @@
+// Package export streams the blocks, transactions and receipts of a channel
+// into JSON Lines or flattened CSV files for loading into a warehouse.
+//
+// An export writes the tables blocks, transactions and receipts into files
+// of those names in an output directory, together with a checkpoint file
+// recording the last exported height and the size of every table. An export
+// interrupted at any point resumes from the checkpoint and first truncates
+// the tables to the recorded sizes, so no rows are duplicated or lost.
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kochavalabs/mazzaroth-go"
+	"github.com/kochavalabs/mazzaroth-go/verify"
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+// Format is the file format of an export.
+type Format string
+
+const (
+	// FormatJSONLines writes one json object per line.
+	FormatJSONLines Format = "jsonl"
+	// FormatCSV writes flattened rows with a header line.
+	FormatCSV Format = "csv"
+)
+
+// checkpointFile is the name of the checkpoint in the output directory.
+const checkpointFile = "checkpoint.json"
+
+// defaultBatch is the number of blocks requested per BlockList call and
+// written between checkpoints.
+const defaultBatch = 50
+
+var (
+	// ErrUnknownFormat triggered if an unsupported format is used
+	ErrUnknownFormat = errors.New("unknown export format")
+	// ErrCheckpointMismatch triggered if the output directory holds an export of another channel, format or receipts setting
+	ErrCheckpointMismatch = errors.New("checkpoint belongs to a different export")
+)
+
+// Checkpoint is the progress of an export.
+type Checkpoint struct {
+	ChannelID    string           `json:"channelID"`
+	Format       Format           `json:"format"`
+	SkipReceipts bool             `json:"skipReceipts,omitempty"`
+	Height       uint64           `json:"height,string"`
+	Sizes        map[string]int64 `json:"sizes"`
+}
+
+// Exporter exports a channel into a directory.
+type Exporter struct {
+	client    mazzaroth.Client
+	channelID string
+	dir       string
+	format    Format
+	batch     int
+	receipts  bool
+}
+
+// New creates an exporter writing the channel into dir in format.
+func New(client mazzaroth.Client, channelID string, dir string, format Format) (*Exporter, error) {
+	if format != FormatJSONLines && format != FormatCSV {
+		return nil, ErrUnknownFormat
+	}
+	return &Exporter{
+		client:    client,
+		channelID: channelID,
+		dir:       dir,
+		format:    format,
+		batch:     defaultBatch,
+		receipts:  true,
+	}, nil
+}
+
+// SkipReceipts disables the receipts table, saving a ReceiptLookup per
+// transaction.
+func (e *Exporter) SkipReceipts() *Exporter {
+	e.receipts = false
+	return e
+}
+
+// table is an output file of an export.
+type table struct {
+	name string
+	file *os.File
+	buf  *bufio.Writer
+	csv  *csv.Writer
+}
+
+func (t *table) write(r record) error {
+	if t.csv != nil {
+		row, err := r.csv()
+		if err != nil {
+			return err
+		}
+		return t.csv.Write(row)
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := t.buf.Write(b); err != nil {
+		return err
+	}
+	return t.buf.WriteByte('\n')
+}
+
+// flush writes buffered rows to disk and returns the size of the file.
+func (t *table) flush() (int64, error) {
+	if t.csv != nil {
+		t.csv.Flush()
+		if err := t.csv.Error(); err != nil {
+			return 0, err
+		}
+	}
+	if err := t.buf.Flush(); err != nil {
+		return 0, err
+	}
+	if err := t.file.Sync(); err != nil {
+		return 0, err
+	}
+	return t.file.Seek(0, io.SeekCurrent)
+}
+
+// Export exports the blocks at heights from to to, both inclusive, resuming
+// after the height of an existing checkpoint. It returns the last exported
+// height.
+func (e *Exporter) Export(ctx context.Context, from, to uint64) (uint64, error) {
+	if err := os.MkdirAll(e.dir, 0o755); err != nil {
+		return 0, errors.Wrap(err, "unable to create export directory")
+	}
+
+	checkpoint, err := e.loadCheckpoint()
+	if err != nil {
+		return 0, err
+	}
+	if checkpoint != nil {
+		if checkpoint.ChannelID != e.channelID || checkpoint.Format != e.format || checkpoint.SkipReceipts != !e.receipts {
+			return 0, ErrCheckpointMismatch
+		}
+		if checkpoint.Height >= from {
+			from = checkpoint.Height + 1
+		}
+	} else {
+		checkpoint = &Checkpoint{ChannelID: e.channelID, Format: e.format, SkipReceipts: !e.receipts, Sizes: map[string]int64{}}
+	}
+	if from > to {
+		return checkpoint.Height, nil
+	}
+
+	tables, err := e.openTables(checkpoint)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		for _, t := range tables {
+			t.file.Close()
+		}
+	}()
+
+	for height := from; height <= to; {
+		number := e.batch
+		if remaining := to - height + 1; remaining < uint64(number) {
+			number = int(remaining)
+		}
+		blocks, err := e.client.BlockList(ctx, e.channelID, int(height), number)
+		if err != nil {
+			return checkpoint.Height, errors.Wrap(err, "unable to list blocks")
+		}
+		if len(blocks) == 0 {
+			break
+		}
+
+		sort.Slice(blocks, func(i, j int) bool {
+			return blocks[i].Header.BlockHeight < blocks[j].Header.BlockHeight
+		})
+		next := height
+		for i := range blocks {
+			block := &blocks[i]
+			if block.Header.BlockHeight != next || block.Header.BlockHeight > to {
+				continue
+			}
+			if err := e.writeBlock(ctx, tables, block); err != nil {
+				return checkpoint.Height, err
+			}
+			next++
+		}
+		if next == height {
+			return checkpoint.Height, errors.Errorf("node did not return block %d", height)
+		}
+		height = next
+
+		for _, t := range tables {
+			size, err := t.flush()
+			if err != nil {
+				return checkpoint.Height, errors.Wrapf(err, "unable to write %s", t.name)
+			}
+			checkpoint.Sizes[t.name] = size
+		}
+		checkpoint.Height = height - 1
+		if err := e.saveCheckpoint(checkpoint); err != nil {
+			return checkpoint.Height, err
+		}
+	}
+
+	// csv headers are written even if the node returned no blocks
+	for _, t := range tables {
+		if _, err := t.flush(); err != nil {
+			return checkpoint.Height, errors.Wrapf(err, "unable to write %s", t.name)
+		}
+	}
+	return checkpoint.Height, nil
+}
+
+func (e *Exporter) writeBlock(ctx context.Context, tables map[string]*table, block *xdr.Block) error {
+	height := block.Header.BlockHeight
+	if err := tables["blocks"].write(&blockRecord{Header: block.Header, TransactionCount: len(block.Transactions)}); err != nil {
+		return errors.Wrap(err, "unable to write block")
+	}
+
+	for i := range block.Transactions {
+		id, err := verify.TransactionID(&block.Transactions[i])
+		if err != nil {
+			return err
+		}
+		if err := tables["transactions"].write(&transactionRecord{ID: id, BlockHeight: height, Index: i, Transaction: block.Transactions[i]}); err != nil {
+			return errors.Wrap(err, "unable to write transaction")
+		}
+
+		if !e.receipts {
+			continue
+		}
+		receipt, err := e.client.ReceiptLookup(ctx, e.channelID, hex.EncodeToString(id[:]))
+		if err != nil {
+			return errors.Wrap(err, "unable to lookup receipt")
+		}
+		if err := tables["receipts"].write(&receiptRecord{BlockHeight: height, Index: i, Receipt: *receipt}); err != nil {
+			return errors.Wrap(err, "unable to write receipt")
+		}
+	}
+	return nil
+}
+
+// openTables opens the output files, truncating them to the sizes recorded
+// by the checkpoint.
+func (e *Exporter) openTables(checkpoint *Checkpoint) (map[string]*table, error) {
+	names := map[string][]string{
+		"blocks":       blockColumns,
+		"transactions": transactionColumns,
+	}
+	if e.receipts {
+		names["receipts"] = receiptColumns
+	}
+
+	tables := make(map[string]*table)
+	for name, columns := range names {
+		f, err := os.OpenFile(filepath.Join(e.dir, name+"."+string(e.format)), os.O_CREATE|os.O_RDWR, 0o644)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to open %s", name)
+		}
+		t := &table{name: name, file: f, buf: bufio.NewWriter(f)}
+		tables[name] = t
+
+		size := checkpoint.Sizes[name]
+		if err := f.Truncate(size); err != nil {
+			return nil, errors.Wrapf(err, "unable to truncate %s", name)
+		}
+		if _, err := f.Seek(size, io.SeekStart); err != nil {
+			return nil, errors.Wrapf(err, "unable to seek %s", name)
+		}
+
+		if e.format == FormatCSV {
+			t.csv = csv.NewWriter(t.buf)
+			if size == 0 {
+				if err := t.csv.Write(columns); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return tables, nil
+}
+
+func (e *Exporter) loadCheckpoint() (*Checkpoint, error) {
+	b, err := os.ReadFile(filepath.Join(e.dir, checkpointFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "unable to read checkpoint")
+	}
+	checkpoint := &Checkpoint{}
+	if err := json.Unmarshal(b, checkpoint); err != nil {
+		return nil, errors.Wrap(err, "unable to decode checkpoint")
+	}
+	if checkpoint.Sizes == nil {
+		checkpoint.Sizes = map[string]int64{}
+	}
+	return checkpoint, nil
+}
+
+// saveCheckpoint replaces the checkpoint atomically.
+func (e *Exporter) saveCheckpoint(checkpoint *Checkpoint) error {
+	b, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(e.dir, checkpointFile+".tmp")
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return errors.Wrap(err, "unable to write checkpoint")
+	}
+	return errors.Wrap(os.Rename(tmp, filepath.Join(e.dir, checkpointFile)), "unable to write checkpoint")
+}
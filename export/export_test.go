@@ -0,0 +1,207 @@
+package export
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kochavalabs/mazzaroth-go"
+	"github.com/kochavalabs/mazzaroth-go/verify"
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+var errTestNode = errors.New("node unavailable")
+
+// testNode serves blocks at heights 1 to 120 with two transactions each and
+// fails every receipt lookup once failAfter lookups were made.
+type testNode struct {
+	mazzaroth.Client
+	blocks    []xdr.Block
+	lookups   int
+	failAfter int
+}
+
+func newTestNode(t *testing.T) *testNode {
+	privateKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	sender, _ := xdr.IDFromPublicKey(privateKey.Public())
+
+	node := &testNode{failAfter: -1}
+	for height := uint64(1); height <= 120; height++ {
+		block := xdr.Block{Header: xdr.BlockHeader{BlockHeight: height, Status: xdr.StatusFINALIZED}}
+		for i := uint64(0); i < 2; i++ {
+			tx, err := mazzaroth.Transaction(sender, xdr.ID{}).Call(height*2+i, 0).Function("test").Arguments(mazzaroth.String("a,b")).Sign(privateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block.Transactions = append(block.Transactions, *tx)
+		}
+		node.blocks = append(node.blocks, block)
+	}
+	return node
+}
+
+func (n *testNode) BlockList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.Block, error) {
+	var blocks []xdr.Block
+	for _, block := range n.blocks {
+		if block.Header.BlockHeight >= uint64(blockHeight) && len(blocks) < number {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, nil
+}
+
+func (n *testNode) ReceiptLookup(ctx context.Context, channelID string, transactionID string) (*xdr.Receipt, error) {
+	if n.failAfter >= 0 && n.lookups >= n.failAfter {
+		return nil, errTestNode
+	}
+	n.lookups++
+	id, err := xdr.IDFromHexString(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return &xdr.Receipt{TransactionID: id, Status: xdr.StatusSUCCESS, Result: "ok"}, nil
+}
+
+func readTables(t *testing.T, dir string, format Format) map[string]string {
+	tables := make(map[string]string)
+	for _, name := range []string{"blocks", "transactions", "receipts"} {
+		b, err := os.ReadFile(filepath.Join(dir, name+"."+string(format)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tables[name] = string(b)
+	}
+	return tables
+}
+
+func TestExportResume(t *testing.T) {
+	for _, format := range []Format{FormatJSONLines, FormatCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			ctx := context.Background()
+
+			want := t.TempDir()
+			exporter, err := New(newTestNode(t), "channel", want, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if height, err := exporter.Export(ctx, 1, 120); err != nil || height != 120 {
+				t.Fatalf("expected export up to 120, got: %d %v", height, err)
+			}
+
+			got := t.TempDir()
+			node := newTestNode(t)
+			node.failAfter = 130
+			exporter, err = New(node, "channel", got, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			height, err := exporter.Export(ctx, 1, 120)
+			if !errors.Is(err, errTestNode) || height != 50 {
+				t.Fatalf("expected export to fail after 50, got: %d %v", height, err)
+			}
+
+			node.failAfter = -1
+			if height, err := exporter.Export(ctx, 1, 120); err != nil || height != 120 {
+				t.Fatalf("expected export up to 120, got: %d %v", height, err)
+			}
+
+			wantTables, gotTables := readTables(t, want, format), readTables(t, got, format)
+			for name := range wantTables {
+				if wantTables[name] != gotTables[name] {
+					t.Fatalf("%s differs after resuming", name)
+				}
+			}
+		})
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	dir := t.TempDir()
+	node := newTestNode(t)
+	exporter, err := New(node, "channel", dir, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exporter.SkipReceipts().Export(context.Background(), 5, 6); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "receipts.csv")); !os.IsNotExist(err) {
+		t.Fatal("expected no receipts table")
+	}
+
+	f, err := os.Open(filepath.Join(dir, "transactions.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("expected a header and 4 rows, got: %d", len(rows))
+	}
+	if strings.Join(rows[0], ",") != strings.Join(transactionColumns, ",") {
+		t.Fatalf("unexpected header: %v", rows[0])
+	}
+
+	id, err := verify.TransactionID(&node.blocks[4].Transactions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := rows[1]
+	if row[0] != hex.EncodeToString(id[:]) || row[1] != "5" || row[8] != "CALL" || row[9] != "test" || row[10] != `["a,b"]` {
+		t.Fatalf("unexpected row: %v", row)
+	}
+}
+
+func TestExportCheckpointMismatch(t *testing.T) {
+	dir := t.TempDir()
+	exporter, err := New(newTestNode(t), "channel", dir, FormatJSONLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exporter.Export(context.Background(), 1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	exporter, err = New(newTestNode(t), "other", dir, FormatJSONLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exporter.Export(context.Background(), 1, 2); err != ErrCheckpointMismatch {
+		t.Fatalf("expected checkpoint mismatch, got: %v", err)
+	}
+
+	exporter, err = New(newTestNode(t), "channel", dir, FormatJSONLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exporter.SkipReceipts().Export(context.Background(), 1, 3); err != ErrCheckpointMismatch {
+		t.Fatalf("expected checkpoint mismatch skipping receipts, got: %v", err)
+	}
+}
+
+func TestExportCSVNoBlocks(t *testing.T) {
+	dir := t.TempDir()
+	exporter, err := New(newTestNode(t), "channel", dir, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exporter.Export(context.Background(), 200, 201); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "blocks.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != strings.Join(blockColumns, ",")+"\n" {
+		t.Fatalf("expected only the header, got: %q", b)
+	}
+}
@@ -0,0 +1,129 @@
+package export
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+// record is a row of an exported table.
+type record interface {
+	// csv returns the flattened columns of the record, in the order of the
+	// header of its table.
+	csv() ([]string, error)
+}
+
+// blockRecord is a row of the blocks table.
+type blockRecord struct {
+	Header           xdr.BlockHeader `json:"header"`
+	TransactionCount int             `json:"transactionCount"`
+}
+
+var blockColumns = []string{
+	"block_height", "transaction_height", "consensus_sequence_number",
+	"transactions_merkle_root", "transactions_receipt_root", "state_root",
+	"previous_header", "status", "transaction_count",
+}
+
+func (r *blockRecord) csv() ([]string, error) {
+	h := r.Header
+	return []string{
+		strconv.FormatUint(h.BlockHeight, 10),
+		strconv.FormatUint(h.TransactionHeight, 10),
+		strconv.FormatUint(h.ConsensusSequenceNumber, 10),
+		hex.EncodeToString(h.TransactionsMerkleRoot[:]),
+		hex.EncodeToString(h.TransactionsReceiptRoot[:]),
+		hex.EncodeToString(h.StateRoot[:]),
+		hex.EncodeToString(h.PreviousHeader[:]),
+		strings.TrimPrefix(h.Status.String(), "Status"),
+		strconv.Itoa(r.TransactionCount),
+	}, nil
+}
+
+// transactionRecord is a row of the transactions table.
+type transactionRecord struct {
+	ID          xdr.ID          `json:"id"`
+	BlockHeight uint64          `json:"blockHeight,string"`
+	Index       int             `json:"index"`
+	Transaction xdr.Transaction `json:"transaction"`
+}
+
+var transactionColumns = []string{
+	"id", "block_height", "index", "sender", "signature", "channel_id",
+	"nonce", "block_expiration_number", "category", "function", "arguments",
+	"contract_version", "contract_owner", "contract_hash", "pause",
+}
+
+func (r *transactionRecord) csv() ([]string, error) {
+	tx := r.Transaction
+	category := tx.Data.Category
+
+	var function, arguments, version, owner, hash, pause string
+	switch category.Type {
+	case xdr.CategoryTypeCALL:
+		if category.Call != nil {
+			function = category.Call.Function
+			b, err := json.Marshal(category.Call.Arguments)
+			if err != nil {
+				return nil, err
+			}
+			arguments = string(b)
+		}
+	case xdr.CategoryTypeDEPLOY:
+		if category.Contract != nil {
+			version = category.Contract.Version
+			owner = hex.EncodeToString(category.Contract.Owner[:])
+			hash = hex.EncodeToString(category.Contract.ContractHash[:])
+		}
+	case xdr.CategoryTypePAUSE:
+		if category.Pause != nil {
+			pause = strconv.FormatBool(*category.Pause)
+		}
+	}
+
+	return []string{
+		hex.EncodeToString(r.ID[:]),
+		strconv.FormatUint(r.BlockHeight, 10),
+		strconv.Itoa(r.Index),
+		hex.EncodeToString(tx.Sender[:]),
+		hex.EncodeToString(tx.Signature[:]),
+		hex.EncodeToString(tx.Data.ChannelID[:]),
+		strconv.FormatUint(tx.Data.Nonce, 10),
+		strconv.FormatUint(tx.Data.BlockExpirationNumber, 10),
+		strings.TrimPrefix(category.Type.String(), "CategoryType"),
+		function,
+		arguments,
+		version,
+		owner,
+		hash,
+		pause,
+	}, nil
+}
+
+// receiptRecord is a row of the receipts table.
+type receiptRecord struct {
+	BlockHeight uint64      `json:"blockHeight,string"`
+	Index       int         `json:"index"`
+	Receipt     xdr.Receipt `json:"receipt"`
+}
+
+var receiptColumns = []string{
+	"transaction_id", "block_height", "index", "status", "state_root",
+	"result", "status_info",
+}
+
+func (r *receiptRecord) csv() ([]string, error) {
+	receipt := r.Receipt
+	return []string{
+		hex.EncodeToString(receipt.TransactionID[:]),
+		strconv.FormatUint(r.BlockHeight, 10),
+		strconv.Itoa(r.Index),
+		strings.TrimPrefix(receipt.Status.String(), "Status"),
+		hex.EncodeToString(receipt.StateRoot[:]),
+		receipt.Result,
+		string(receipt.StatusInfo),
+	}, nil
+}
@@ -1,9 +1,25 @@
 module github.com/kochavalabs/mazzaroth-go
 
-go 1.16
+go 1.20
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/kochavalabs/crypto v0.1.2
 	github.com/kochavalabs/mazzaroth-xdr v0.8.1
 	github.com/pkg/errors v0.9.1
+	go.etcd.io/bbolt v1.3.6
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/metric v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/sdk/metric v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/stellar/go-xdr v0.0.0-20211004181054-b95df30963cd // indirect
+	golang.org/x/crypto v0.0.0-20190219172222-a4c6cb3142f2 // indirect
+	golang.org/x/sys v0.17.0 // indirect
 )
@@ -0,0 +1,212 @@
+package mazzaroth
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+// HeaderStore persists the block headers trusted by a LightClient. Headers
+// of a channel are appended in height order without gaps.
+type HeaderStore interface {
+	// Latest returns the highest trusted header of a channel or ErrNotFound.
+	Latest(channelID string) (*xdr.BlockHeader, error)
+	// Header returns the trusted header at height or ErrNotFound.
+	Header(channelID string, height uint64) (*xdr.BlockHeader, error)
+	// Append adds headers following the latest trusted header.
+	Append(channelID string, headers []xdr.BlockHeader) error
+}
+
+var (
+	_ HeaderStore = &MemoryHeaderStore{}
+	_ HeaderStore = &FileHeaderStore{}
+)
+
+// MemoryHeaderStore keeps trusted headers in memory.
+type MemoryHeaderStore struct {
+	mu      sync.RWMutex
+	headers map[string][]xdr.BlockHeader
+}
+
+// NewMemoryHeaderStore creates an empty in memory header store.
+func NewMemoryHeaderStore() *MemoryHeaderStore {
+	return &MemoryHeaderStore{
+		headers: make(map[string][]xdr.BlockHeader),
+	}
+}
+
+// Latest implements HeaderStore.
+func (s *MemoryHeaderStore) Latest(channelID string) (*xdr.BlockHeader, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	headers := s.headers[channelID]
+	if len(headers) == 0 {
+		return nil, ErrNotFound
+	}
+	header := headers[len(headers)-1]
+	return &header, nil
+}
+
+// Header implements HeaderStore.
+func (s *MemoryHeaderStore) Header(channelID string, height uint64) (*xdr.BlockHeader, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	headers := s.headers[channelID]
+	if len(headers) == 0 || height < headers[0].BlockHeight {
+		return nil, ErrNotFound
+	}
+	i := height - headers[0].BlockHeight
+	if i >= uint64(len(headers)) {
+		return nil, ErrNotFound
+	}
+	header := headers[i]
+	return &header, nil
+}
+
+// Append implements HeaderStore.
+func (s *MemoryHeaderStore) Append(channelID string, headers []xdr.BlockHeader) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.headers[channelID] = append(s.headers[channelID], headers...)
+	return nil
+}
+
+// FileHeaderStore keeps trusted headers on disk, in one file of fixed size
+// xdr encoded headers per channel.
+type FileHeaderStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// headerSize is the size of an xdr encoded block header.
+const headerSize = 3*8 + 4*32 + 4
+
+// NewFileHeaderStore creates a header store writing to dir, which is created
+// if it does not exist.
+func NewFileHeaderStore(dir string) (*FileHeaderStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "unable to create header store directory")
+	}
+	return &FileHeaderStore{dir: dir}, nil
+}
+
+func (s *FileHeaderStore) path(channelID string) string {
+	return filepath.Join(s.dir, filepath.Base(channelID)+".headers")
+}
+
+// readAt reads the i-th header of a channel file.
+func readAt(f *os.File, i int64) (*xdr.BlockHeader, error) {
+	b := make([]byte, headerSize)
+	if _, err := f.ReadAt(b, i*headerSize); err != nil {
+		if err == io.EOF {
+			return nil, ErrNotFound
+		}
+		return nil, errors.Wrap(err, "unable to read header")
+	}
+	header := &xdr.BlockHeader{}
+	if err := header.UnmarshalBinary(b); err != nil {
+		return nil, errors.Wrap(err, "unable to decode header")
+	}
+	return header, nil
+}
+
+// open opens the file of a channel and returns the number of headers in it.
+func (s *FileHeaderStore) open(channelID string) (*os.File, int64, error) {
+	f, err := os.Open(s.path(channelID))
+	if os.IsNotExist(err) {
+		return nil, 0, ErrNotFound
+	} else if err != nil {
+		return nil, 0, errors.Wrap(err, "unable to open header store")
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, errors.Wrap(err, "unable to stat header store")
+	}
+	return f, info.Size() / headerSize, nil
+}
+
+// Latest implements HeaderStore.
+func (s *FileHeaderStore) Latest(channelID string) (*xdr.BlockHeader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, n, err := s.open(channelID)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if n == 0 {
+		return nil, ErrNotFound
+	}
+	return readAt(f, n-1)
+}
+
+// Header implements HeaderStore.
+func (s *FileHeaderStore) Header(channelID string, height uint64) (*xdr.BlockHeader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, n, err := s.open(channelID)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if n == 0 {
+		return nil, ErrNotFound
+	}
+	first, err := readAt(f, 0)
+	if err != nil {
+		return nil, err
+	}
+	if height < first.BlockHeight || height-first.BlockHeight >= uint64(n) {
+		return nil, ErrNotFound
+	}
+	return readAt(f, int64(height-first.BlockHeight))
+}
+
+// Append implements HeaderStore.
+func (s *FileHeaderStore) Append(channelID string, headers []xdr.BlockHeader) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := make([]byte, 0, len(headers)*headerSize)
+	for _, header := range headers {
+		hb, err := header.MarshalBinary()
+		if err != nil {
+			return errors.Wrap(err, "in header.MarshalBinary")
+		}
+		b = append(b, hb...)
+	}
+
+	f, err := os.OpenFile(s.path(channelID), os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "unable to open header store")
+	}
+	// drop a header partially written by an interrupted append, so the
+	// new headers start at a header boundary
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return errors.Wrap(err, "unable to stat header store")
+	}
+	size := info.Size() / headerSize * headerSize
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		return errors.Wrap(err, "unable to truncate header store")
+	}
+	if _, err := f.WriteAt(b, size); err != nil {
+		f.Close()
+		return errors.Wrap(err, "unable to write headers")
+	}
+	return f.Close()
+}
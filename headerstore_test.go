@@ -0,0 +1,102 @@
+package mazzaroth
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+func TestHeaderSize(t *testing.T) {
+	b, err := xdr.BlockHeader{}.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != headerSize {
+		t.Fatalf("expected header size %d, got: %d", len(b), headerSize)
+	}
+}
+
+func TestHeaderStores(t *testing.T) {
+	fileStore, err := NewFileHeaderStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, store := range map[string]HeaderStore{
+		"memory": NewMemoryHeaderStore(),
+		"file":   fileStore,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Latest("channel"); err != ErrNotFound {
+				t.Fatalf("expected not found, got: %v", err)
+			}
+
+			headers := []xdr.BlockHeader{{BlockHeight: 5}, {BlockHeight: 6}}
+			headers[1].StateRoot[0] = 1
+			if err := store.Append("channel", headers[:1]); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Append("channel", headers[1:]); err != nil {
+				t.Fatal(err)
+			}
+
+			latest, err := store.Latest("channel")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*latest, headers[1]) {
+				t.Fatalf("expected: %v, got: %v", headers[1], latest)
+			}
+
+			header, err := store.Header("channel", 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*header, headers[0]) {
+				t.Fatalf("expected: %v, got: %v", headers[0], header)
+			}
+
+			for _, height := range []uint64{4, 7} {
+				if _, err := store.Header("channel", height); err != ErrNotFound {
+					t.Fatalf("height %d: expected not found, got: %v", height, err)
+				}
+			}
+			if _, err := store.Header("other", 5); err != ErrNotFound {
+				t.Fatalf("expected not found, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestFileHeaderStoreTornAppend(t *testing.T) {
+	store, err := NewFileHeaderStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append("channel", []xdr.BlockHeader{{BlockHeight: 5}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// an append interrupted after writing part of a header
+	f, err := os.OpenFile(store.path("channel"), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(make([]byte, headerSize/2))
+	f.Close()
+
+	header := xdr.BlockHeader{BlockHeight: 6}
+	header.StateRoot[0] = 1
+	if err := store.Append("channel", []xdr.BlockHeader{header}); err != nil {
+		t.Fatal(err)
+	}
+	latest, err := store.Latest("channel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*latest, header) {
+		t.Fatalf("expected: %v, got: %v", header, latest)
+	}
+}
@@ -0,0 +1,174 @@
+package mazzaroth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NodeHealth is the result of probing a single node.
+type NodeHealth struct {
+	Address string `json:"address"`
+	// Healthy is true if the node answered and is at most the configured
+	// number of blocks behind the highest node.
+	Healthy bool          `json:"healthy"`
+	Height  uint64        `json:"height"`
+	Lag     uint64        `json:"lag"`
+	Latency time.Duration `json:"latency"`
+	Circuit CircuitState  `json:"circuit"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Health is the result of probing every node of a client.
+type Health struct {
+	// Height is the highest block height reported by any node.
+	Height uint64       `json:"height"`
+	Nodes  []NodeHealth `json:"nodes"`
+	// CheckedAt is when the nodes were probed.
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Healthy reports whether at least one node is healthy.
+func (h *Health) Healthy() bool {
+	for _, n := range h.Nodes {
+		if n.Healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// healthConfig is the configuration of the health checks of a client.
+type healthConfig struct {
+	channelID string
+	maxLag    uint64
+}
+
+// Health probes every node with a BlockHeight call on the channel set by
+// WithHealthCheck and reports their latency and how far each is behind the
+// highest. Nodes found down or lagging are skipped by reads until the next
+// check finds them healthy again. Probes bypass the circuit breakers and do
+// not count toward them.
+func (c *ClientImpl) Health(ctx context.Context) (*Health, error) {
+	if c.health.channelID == "" {
+		return nil, ErrNoHealthChannel
+	}
+
+	health := &Health{Nodes: make([]NodeHealth, len(c.nodes.nodes)), CheckedAt: time.Now()}
+	var wg sync.WaitGroup
+	for i, n := range c.nodes.nodes {
+		wg.Add(1)
+		go func(i int, n *node) {
+			defer wg.Done()
+
+			info := RequestInfo{Endpoint: "BlockHeight", ChannelID: c.health.channelID}
+			path := "/" + version + "/channels/" + c.health.channelID + "/blocks/height"
+			begin := time.Now()
+			xdrResp, _, _, err := c.probeNode(ctx, n, info, path, http.MethodGet, nil)
+			nh := NodeHealth{Address: n.address, Latency: time.Since(begin)}
+			if err == nil && xdrResp.Height == nil {
+				err = errors.New("missing block height")
+			}
+			if err != nil {
+				nh.Error = err.Error()
+			} else {
+				nh.Height = xdrResp.Height.Height
+				nh.Healthy = true
+			}
+			health.Nodes[i] = nh
+		}(i, n)
+	}
+	wg.Wait()
+
+	for _, nh := range health.Nodes {
+		if nh.Healthy && nh.Height > health.Height {
+			health.Height = nh.Height
+		}
+	}
+	for i, n := range c.nodes.nodes {
+		nh := &health.Nodes[i]
+		if nh.Healthy {
+			nh.Lag = health.Height - nh.Height
+			nh.Healthy = nh.Lag <= c.health.maxLag
+		}
+		nh.Circuit = n.breaker.current()
+		n.setHealthy(nh.Healthy)
+	}
+	return health, nil
+}
+
+// HealthChecker checks the health of the nodes of a client periodically and
+// serves the latest result as a readiness probe.
+type HealthChecker struct {
+	client   *ClientImpl
+	interval time.Duration
+
+	mu     sync.Mutex
+	latest *Health
+}
+
+// NewHealthChecker creates a health checker for client checking its nodes
+// every interval.
+func NewHealthChecker(client *ClientImpl, interval time.Duration) *HealthChecker {
+	return &HealthChecker{
+		client:   client,
+		interval: interval,
+	}
+}
+
+// Check checks the health of the nodes once.
+func (hc *HealthChecker) Check(ctx context.Context) (*Health, error) {
+	health, err := hc.client.Health(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hc.mu.Lock()
+	hc.latest = health
+	hc.mu.Unlock()
+	return health, nil
+}
+
+// Run checks the health of the nodes until ctx is done.
+func (hc *HealthChecker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(hc.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := hc.Check(ctx); err != nil && ctx.Err() == nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Latest returns the result of the latest check, nil if there was none.
+func (hc *HealthChecker) Latest() *Health {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	return hc.latest
+}
+
+// ServeHTTP serves the latest check as json, with http.StatusOK if at least
+// one node is healthy and http.StatusServiceUnavailable otherwise.
+func (hc *HealthChecker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	health := hc.Latest()
+	status := http.StatusOK
+	if health == nil {
+		health = &Health{}
+	}
+	if !health.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(health)
+}
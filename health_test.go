@@ -0,0 +1,128 @@
+package mazzaroth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+// heightNode serves a fixed block height and counts the other requests.
+type heightNode struct {
+	height uint64
+	reads  int32
+}
+
+func (n *heightNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/channels/ab/blocks/height" {
+		atomic.AddInt32(&n.reads, 1)
+		(&xdrNode{}).ServeHTTP(w, r)
+		return
+	}
+	resp, _ := xdr.NewResponse(xdr.ResponseTypeHEIGHT, xdr.BlockHeight{Height: n.height})
+	b, _ := resp.MarshalJSON()
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.Write(b)
+}
+
+func TestHealth(t *testing.T) {
+	ahead := &heightNode{height: 10}
+	behind := &heightNode{height: 5}
+	aheadServer := httptest.NewServer(ahead)
+	defer aheadServer.Close()
+	behindServer := httptest.NewServer(behind)
+	defer behindServer.Close()
+	deadServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer deadServer.Close()
+
+	client, err := NewMazzarothClient(WithAddresses(aheadServer.URL, behindServer.URL, deadServer.URL), WithHealthCheck("ab", 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	health, err := client.Health(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.Height != 10 || !health.Healthy() {
+		t.Fatalf("unexpected health: %+v", health)
+	}
+	want := []NodeHealth{
+		{Address: aheadServer.URL, Healthy: true, Height: 10},
+		{Address: behindServer.URL, Height: 5, Lag: 5},
+		{Address: deadServer.URL},
+	}
+	for i, nh := range health.Nodes {
+		if nh.Address != want[i].Address || nh.Healthy != want[i].Healthy || nh.Height != want[i].Height || nh.Lag != want[i].Lag {
+			t.Errorf("unexpected health of node %d: %+v", i, nh)
+		}
+	}
+	if health.Nodes[2].Error == "" {
+		t.Error("expected an error for the dead node")
+	}
+
+	// reads only go to the healthy node now
+	for i := 0; i < 4; i++ {
+		if _, err := client.ReceiptLookup(ctx, "ab", "01"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ahead.reads != 4 || behind.reads != 0 {
+		t.Fatalf("expected reads to skip unhealthy nodes, got %d and %d", ahead.reads, behind.reads)
+	}
+
+	if _, err := NewHealthChecker(&ClientImpl{}, time.Second).Check(ctx); err != ErrNoHealthChannel {
+		t.Fatalf("expected no health channel, got: %v", err)
+	}
+}
+
+func TestHealthCheckerReadiness(t *testing.T) {
+	node := &heightNode{height: 3}
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	client, err := NewMazzarothClient(WithAddress(server.URL), WithHealthCheck("ab", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hc := NewHealthChecker(client, time.Second)
+
+	recorder := httptest.NewRecorder()
+	hc.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected unavailable before the first check, got: %d", recorder.Code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- hc.Run(ctx) }()
+	for hc.Latest() == nil {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	recorder = httptest.NewRecorder()
+	hc.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected ready, got: %d", recorder.Code)
+	}
+	var health struct {
+		Height uint64
+		Nodes  []struct{ Circuit string }
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if health.Height != 3 || len(health.Nodes) != 1 || health.Nodes[0].Circuit != "CircuitClosed" {
+		t.Fatalf("unexpected readiness body: %+v", health)
+	}
+}
@@ -0,0 +1,89 @@
+package mazzaroth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+func TestHedging(t *testing.T) {
+	var cancelled, slowRequests, fastRequests int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&slowRequests, 1)
+		select {
+		case <-time.After(300 * time.Millisecond):
+			(&xdrNode{}).ServeHTTP(w, r)
+		case <-r.Context().Done():
+			atomic.AddInt32(&cancelled, 1)
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fastRequests, 1)
+		(&xdrNode{}).ServeHTTP(w, r)
+	}))
+	defer fast.Close()
+
+	client, err := NewMazzarothClient(WithAddresses(slow.URL, fast.URL), WithHedging(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first request goes to the slow node and is hedged to the fast one
+	begin := time.Now()
+	if _, err := client.ReceiptLookup(context.Background(), "ab", "01"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(begin); elapsed > 200*time.Millisecond {
+		t.Fatalf("expected the hedged request to answer, took: %s", elapsed)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&cancelled) != 1 {
+		t.Fatal("expected the slow request to be cancelled")
+	}
+
+	// submissions are never hedged
+	atomic.StoreInt32(&slowRequests, 0)
+	atomic.StoreInt32(&fastRequests, 0)
+	tx := &xdr.Transaction{Data: xdr.Data{Category: xdr.Category{Type: xdr.CategoryTypeDELETE}}}
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.TransactionSubmit(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if atomic.LoadInt32(&slowRequests) != 1 || atomic.LoadInt32(&fastRequests) != 1 {
+		t.Fatalf("unexpected requests: %d slow, %d fast", slowRequests, fastRequests)
+	}
+}
+
+func TestHedgingOpensCircuit(t *testing.T) {
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hung.Close()
+	alive := httptest.NewServer(&xdrNode{})
+	defer alive.Close()
+
+	client, err := NewMazzarothClient(WithAddresses(hung.URL, alive.URL), WithHedging(10*time.Millisecond), WithCircuitBreaker(0.5, 2, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every request the hung node gets first is hedged to the other one
+	for i := 0; i < 6; i++ {
+		if _, err := client.ReceiptLookup(context.Background(), "ab", "01"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state := client.nodes.nodes[0].breaker.current(); state != CircuitOpen {
+		t.Fatalf("expected the circuit of the hung node to open, got: %v", state)
+	}
+	if state := client.nodes.nodes[1].breaker.current(); state != CircuitClosed {
+		t.Fatalf("expected the circuit of the other node to stay closed, got: %v", state)
+	}
+}
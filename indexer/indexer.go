@@ -0,0 +1,139 @@
+// Package indexer ingests the blocks of a channel into a local store and
+// indexes their transactions by sender, called function, category and block
+// height, queries a readonly node can not answer since it only supports
+// lookups by id.
+package indexer
+
+import (
+	"context"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-go"
+	"github.com/pkg/errors"
+)
+
+// defaultBatch is the number of blocks requested per BlockList call.
+const defaultBatch = 50
+
+// maxRetryDelay caps the delay Run backs off to while syncs fail.
+const maxRetryDelay = 5 * time.Minute
+
+// Indexer follows a channel and ingests its blocks into a Store.
+type Indexer struct {
+	client    mazzaroth.Client
+	store     *Store
+	channelID string
+	start     uint64
+	batch     int
+	logger    mazzaroth.Logger
+}
+
+// storeError is a failure of the store, which retrying does not fix.
+type storeError struct {
+	err error
+}
+
+func (e *storeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *storeError) Cause() error {
+	return e.err
+}
+
+func (e *storeError) Unwrap() error {
+	return e.err
+}
+
+// New creates an indexer ingesting the blocks of channelID, starting at
+// block height start when the store does not hold the channel yet.
+func New(client mazzaroth.Client, store *Store, channelID string, start uint64) *Indexer {
+	return &Indexer{
+		client:    client,
+		store:     store,
+		channelID: channelID,
+		start:     start,
+		batch:     defaultBatch,
+	}
+}
+
+// WithLogger sets the logger Run reports failed syncs to.
+func (ix *Indexer) WithLogger(logger mazzaroth.Logger) *Indexer {
+	ix.logger = logger
+	return ix
+}
+
+// Sync ingests all blocks up to the current height of the node and returns
+// the latest indexed height.
+func (ix *Indexer) Sync(ctx context.Context) (uint64, error) {
+	next := ix.start
+	indexed, ok, err := ix.store.Height(ix.channelID)
+	if err != nil {
+		return 0, &storeError{err: err}
+	}
+	if ok {
+		next = indexed + 1
+	}
+
+	height, err := ix.client.BlockHeight(ctx, ix.channelID)
+	if err != nil {
+		return indexed, errors.Wrap(err, "unable to get block height")
+	}
+
+	for next <= height.Height {
+		blocks, err := ix.client.BlockList(ctx, ix.channelID, int(next), ix.batch)
+		if err != nil {
+			return indexed, errors.Wrap(err, "unable to list blocks")
+		}
+		if len(blocks) == 0 {
+			break
+		}
+		if err := ix.store.Put(ix.channelID, ix.start, blocks); err == ErrHeightGap {
+			return indexed, errors.Wrap(err, "node returned blocks out of order")
+		} else if err != nil {
+			return indexed, &storeError{err: errors.Wrap(err, "unable to index blocks")}
+		}
+		indexed = blocks[len(blocks)-1].Header.BlockHeight
+		next = indexed + 1
+	}
+
+	return indexed, nil
+}
+
+// Run syncs the channel every interval until ctx is done. Failed syncs are
+// logged and retried, backing off up to maxRetryDelay, only failures of the
+// store stop Run.
+func (ix *Indexer) Run(ctx context.Context, interval time.Duration) error {
+	delay := interval
+	for {
+		height, err := ix.Sync(ctx)
+		var se *storeError
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.As(err, &se):
+			return err
+		case err != nil:
+			delay *= 2
+			if delay > maxRetryDelay {
+				delay = maxRetryDelay
+			}
+			if delay < interval {
+				delay = interval
+			}
+			if ix.logger != nil {
+				ix.logger.Warn("unable to sync channel, retrying", "channel", ix.channelID, "height", height, "retry", delay, "error", err)
+			}
+		default:
+			delay = interval
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package indexer
+
+import (
+	"context"
+	"crypto/ed25519"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-go"
+	"github.com/kochavalabs/mazzaroth-go/verify"
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+// testNode serves a fixed list of blocks, failing the first failures
+// BlockHeight calls.
+type testNode struct {
+	mazzaroth.Client
+	blocks   []xdr.Block
+	failures int32
+}
+
+func (n *testNode) BlockHeight(ctx context.Context, channelID string) (*xdr.BlockHeight, error) {
+	if atomic.AddInt32(&n.failures, -1) >= 0 {
+		return nil, errors.New("node unavailable")
+	}
+	return &xdr.BlockHeight{Height: uint64(len(n.blocks))}, nil
+}
+
+func (n *testNode) BlockList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.Block, error) {
+	var blocks []xdr.Block
+	for _, block := range n.blocks {
+		if block.Header.BlockHeight >= uint64(blockHeight) && len(blocks) < number {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, nil
+}
+
+func testKey(seed byte) (ed25519.PrivateKey, xdr.ID) {
+	s := make([]byte, ed25519.SeedSize)
+	s[0] = seed
+	privateKey := ed25519.NewKeyFromSeed(s)
+	id, _ := xdr.IDFromPublicKey(privateKey.Public())
+	return privateKey, id
+}
+
+// newTestNode creates blocks at heights 1 to 120, each with a call of "a"
+// from the first account, a call of "b" from the second account and on every
+// tenth block a pause from the first account.
+func newTestNode(t *testing.T) *testNode {
+	aliceKey, alice := testKey(1)
+	bobKey, bob := testKey(2)
+
+	node := &testNode{}
+	for height := uint64(1); height <= 120; height++ {
+		block := xdr.Block{Header: xdr.BlockHeader{BlockHeight: height}}
+
+		tx, err := mazzaroth.Transaction(alice, xdr.ID{}).Call(height, 0).Function("a").Sign(aliceKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.Transactions = append(block.Transactions, *tx)
+
+		tx, err = mazzaroth.Transaction(bob, xdr.ID{}).Call(height, 0).Function("b").Sign(bobKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.Transactions = append(block.Transactions, *tx)
+
+		if height%10 == 0 {
+			tx, err = mazzaroth.Transaction(alice, xdr.ID{}).Contract(height, 0).Pause(true).Sign(aliceKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block.Transactions = append(block.Transactions, *tx)
+		}
+		node.blocks = append(node.blocks, block)
+	}
+	return node
+}
+
+func TestIndexer(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	node := newTestNode(t)
+	ix := New(node, store, "channel", 1)
+	height, err := ix.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 120 {
+		t.Fatalf("expected height 120, got: %d", height)
+	}
+
+	_, alice := testKey(1)
+	_, bob := testKey(2)
+	for _, test := range []struct {
+		name  string
+		query Query
+		want  int
+	}{
+		{"all", Query{}, 252},
+		{"sender", Query{Sender: &alice}, 132},
+		{"function", Query{Function: "b"}, 120},
+		{"category", Query{Category: xdr.CategoryTypePAUSE}, 12},
+		{"heights", Query{FromHeight: 11, ToHeight: 20}, 21},
+		{"combined", Query{Sender: &alice, Category: xdr.CategoryTypeCALL, FromHeight: 100}, 21},
+		{"no match", Query{Sender: &bob, Function: "a"}, 0},
+		{"limit", Query{Function: "a", Limit: 5}, 5},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := store.Transactions("channel", test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != test.want {
+				t.Fatalf("expected %d transactions, got: %d", test.want, len(entries))
+			}
+			for i := 1; i < len(entries); i++ {
+				if entries[i].BlockHeight < entries[i-1].BlockHeight {
+					t.Fatal("expected transactions in chain order")
+				}
+			}
+		})
+	}
+
+	id, err := verify.TransactionID(&node.blocks[41].Transactions[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := store.Transaction("channel", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.BlockHeight != 42 || entry.Index != 1 || entry.ID != id {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if _, err := store.Transaction("channel", xdr.ID{}); err != ErrNotFound {
+		t.Fatalf("expected not found, got: %v", err)
+	}
+}
+
+func TestStoreHeightGap(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	blocks := newTestNode(t).blocks
+	// the first block has to be at the start height
+	if err := store.Put("channel", 1, blocks[1:5]); err != ErrHeightGap {
+		t.Fatalf("expected height gap at the start, got: %v", err)
+	}
+	if _, ok, err := store.Height("channel"); err != nil || ok {
+		t.Fatalf("expected nothing indexed, got: %v %v", ok, err)
+	}
+	if err := store.Put("channel", 1, blocks[:5]); err != nil {
+		t.Fatal(err)
+	}
+	// already indexed blocks are skipped
+	if err := store.Put("channel", 1, blocks[3:6]); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("channel", 1, blocks[7:8]); err != ErrHeightGap {
+		t.Fatalf("expected height gap, got: %v", err)
+	}
+	height, ok, err := store.Height("channel")
+	if err != nil || !ok || height != 6 {
+		t.Fatalf("expected height 6, got: %d %v %v", height, ok, err)
+	}
+}
+
+func TestIndexerRun(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// failing syncs are retried until the node answers
+	node := newTestNode(t)
+	node.failures = 3
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- New(node, store, "channel", 1).Run(ctx, time.Millisecond)
+	}()
+	for {
+		if height, ok, _ := store.Height("channel"); ok && height == 120 {
+			break
+		}
+		if ctx.Err() != nil {
+			t.Fatal("expected the channel to be indexed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected run to stop with the context, got: %v", err)
+	}
+
+	// failures of the store stop run
+	store.Close()
+	if err := New(node, store, "channel", 1).Run(context.Background(), time.Millisecond); err == nil {
+		t.Fatal("expected a store error")
+	}
+}
+
+func TestIndexerStartGap(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// the node has no block at height 0
+	if _, err := New(newTestNode(t), store, "channel", 0).Sync(context.Background()); !errors.Is(err, ErrHeightGap) {
+		t.Fatalf("expected height gap, got: %v", err)
+	}
+}
@@ -0,0 +1,307 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/kochavalabs/mazzaroth-go/verify"
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// ErrNotFound is raised when the searched transaction is not indexed.
+	ErrNotFound = errors.New("transaction not found")
+	// ErrHeightGap is raised when blocks are not ingested in height order without gaps.
+	ErrHeightGap = errors.New("block does not follow the indexed height")
+)
+
+// Buckets nested in the bucket of each channel. Index keys end with the
+// position of a transaction, its block height and index in the block, so
+// that matches of an index are iterated in chain order.
+var (
+	metaBucket     = []byte("meta")
+	txBucket       = []byte("transactions")
+	idBucket       = []byte("ids")
+	senderBucket   = []byte("senders")
+	functionBucket = []byte("functions")
+	categoryBucket = []byte("categories")
+
+	heightKey = []byte("height")
+)
+
+// positionSize is the size of an encoded transaction position.
+const positionSize = 8 + 4
+
+// Store is an embedded, file backed index of the transactions of one or
+// more channels.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the store at path, creating it if it does not exist.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open index")
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Height returns the height of the latest indexed block of a channel. ok is
+// false if no block of the channel has been indexed yet.
+func (s *Store) Height(channelID string) (height uint64, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		channel := tx.Bucket([]byte(channelID))
+		if channel == nil {
+			return nil
+		}
+		if v := channel.Bucket(metaBucket).Get(heightKey); v != nil {
+			height, ok = binary.BigEndian.Uint64(v), true
+		}
+		return nil
+	})
+	return height, ok, err
+}
+
+// Put indexes blocks of a channel. Blocks must follow the latest indexed
+// block without gaps, blocks at or below it are skipped. A channel without
+// indexed blocks has to start at block height start, blocks below it are
+// skipped.
+func (s *Store) Put(channelID string, start uint64, blocks []xdr.Block) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		channel, err := tx.CreateBucketIfNotExists([]byte(channelID))
+		if err != nil {
+			return err
+		}
+		buckets := make(map[string]*bolt.Bucket)
+		for _, name := range [][]byte{metaBucket, txBucket, idBucket, senderBucket, functionBucket, categoryBucket} {
+			b, err := channel.CreateBucketIfNotExists(name)
+			if err != nil {
+				return err
+			}
+			buckets[string(name)] = b
+		}
+
+		meta := buckets[string(metaBucket)]
+		v := meta.Get(heightKey)
+		for _, block := range blocks {
+			height := block.Header.BlockHeight
+			next := start
+			if v != nil {
+				next = binary.BigEndian.Uint64(v) + 1
+			}
+			if height < next {
+				continue
+			}
+			if height != next {
+				return ErrHeightGap
+			}
+
+			for i := range block.Transactions {
+				if err := putTransaction(buckets, height, uint32(i), &block.Transactions[i]); err != nil {
+					return err
+				}
+			}
+
+			v = make([]byte, 8)
+			binary.BigEndian.PutUint64(v, height)
+			if err := meta.Put(heightKey, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func putTransaction(buckets map[string]*bolt.Bucket, height uint64, index uint32, transaction *xdr.Transaction) error {
+	b, err := transaction.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "in transaction.MarshalBinary")
+	}
+	id, err := verify.TransactionID(transaction)
+	if err != nil {
+		return err
+	}
+
+	pos := position(height, index)
+	if err := buckets[string(txBucket)].Put(pos, b); err != nil {
+		return err
+	}
+	if err := buckets[string(idBucket)].Put(id[:], pos); err != nil {
+		return err
+	}
+	if err := buckets[string(senderBucket)].Put(concat(transaction.Sender[:], pos), nil); err != nil {
+		return err
+	}
+	category := transaction.Data.Category
+	if err := buckets[string(categoryBucket)].Put(concat(categoryPrefix(category.Type), pos), nil); err != nil {
+		return err
+	}
+	if category.Type == xdr.CategoryTypeCALL && category.Call != nil {
+		if err := buckets[string(functionBucket)].Put(concat(functionPrefix(category.Call.Function), pos), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Entry is an indexed transaction.
+type Entry struct {
+	ID          xdr.ID          `json:"id"`
+	BlockHeight uint64          `json:"blockHeight,string"`
+	Index       uint32          `json:"index"`
+	Transaction xdr.Transaction `json:"transaction"`
+}
+
+// Transaction returns the indexed transaction with id.
+func (s *Store) Transaction(channelID string, id xdr.ID) (*Entry, error) {
+	var entry *Entry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		channel := tx.Bucket([]byte(channelID))
+		if channel == nil {
+			return ErrNotFound
+		}
+		pos := channel.Bucket(idBucket).Get(id[:])
+		if pos == nil {
+			return ErrNotFound
+		}
+		var err error
+		entry, err = decodeEntry(pos, channel.Bucket(txBucket).Get(pos))
+		return err
+	})
+	return entry, err
+}
+
+// Query selects indexed transactions. Zero valued fields do not restrict the
+// result.
+type Query struct {
+	// Sender only matches transactions sent by this account.
+	Sender *xdr.ID
+	// Function only matches calls of this function.
+	Function string
+	// Category only matches transactions of this category.
+	Category xdr.CategoryType
+	// FromHeight and ToHeight restrict the block height, both inclusive.
+	FromHeight uint64
+	ToHeight   uint64
+	// Limit caps the number of returned transactions.
+	Limit int
+}
+
+func (q *Query) matches(entry *Entry) bool {
+	if q.Sender != nil && entry.Transaction.Sender != *q.Sender {
+		return false
+	}
+	category := entry.Transaction.Data.Category
+	if q.Category != xdr.CategoryTypeUNKNOWN && category.Type != q.Category {
+		return false
+	}
+	if q.Function != "" && (category.Type != xdr.CategoryTypeCALL || category.Call == nil || category.Call.Function != q.Function) {
+		return false
+	}
+	return true
+}
+
+// Transactions returns the transactions of a channel matching q in chain
+// order.
+func (s *Store) Transactions(channelID string, q Query) ([]Entry, error) {
+	var entries []Entry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		channel := tx.Bucket([]byte(channelID))
+		if channel == nil {
+			return nil
+		}
+		txs := channel.Bucket(txBucket)
+
+		// walk the most selective index available and filter the rest
+		var bucket *bolt.Bucket
+		var prefix []byte
+		switch {
+		case q.Sender != nil:
+			bucket, prefix = channel.Bucket(senderBucket), q.Sender[:]
+		case q.Function != "":
+			bucket, prefix = channel.Bucket(functionBucket), functionPrefix(q.Function)
+		case q.Category != xdr.CategoryTypeUNKNOWN:
+			bucket, prefix = channel.Bucket(categoryBucket), categoryPrefix(q.Category)
+		default:
+			bucket, prefix = txs, nil
+		}
+
+		c := bucket.Cursor()
+		for k, v := c.Seek(concat(prefix, position(q.FromHeight, 0))); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			pos := k[len(prefix):]
+			if len(pos) != positionSize {
+				continue
+			}
+			if q.ToHeight != 0 && binary.BigEndian.Uint64(pos) > q.ToHeight {
+				break
+			}
+			if bucket != txs {
+				v = txs.Get(pos)
+			}
+			entry, err := decodeEntry(pos, v)
+			if err != nil {
+				return err
+			}
+			if !q.matches(entry) {
+				continue
+			}
+			entries = append(entries, *entry)
+			if q.Limit > 0 && len(entries) >= q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
+
+func decodeEntry(pos, b []byte) (*Entry, error) {
+	entry := &Entry{
+		BlockHeight: binary.BigEndian.Uint64(pos),
+		Index:       binary.BigEndian.Uint32(pos[8:]),
+	}
+	if err := entry.Transaction.UnmarshalBinary(b); err != nil {
+		return nil, errors.Wrap(err, "unable to decode indexed transaction")
+	}
+	id, err := verify.TransactionID(&entry.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	entry.ID = id
+	return entry, nil
+}
+
+func position(height uint64, index uint32) []byte {
+	pos := make([]byte, positionSize)
+	binary.BigEndian.PutUint64(pos, height)
+	binary.BigEndian.PutUint32(pos[8:], index)
+	return pos
+}
+
+func categoryPrefix(category xdr.CategoryType) []byte {
+	prefix := make([]byte, 4)
+	binary.BigEndian.PutUint32(prefix, uint32(category))
+	return prefix
+}
+
+// functionPrefix terminates the name so that one name is never the prefix
+// of another.
+func functionPrefix(function string) []byte {
+	return append([]byte(function), 0)
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, part := range parts {
+		b = append(b, part...)
+	}
+	return b
+}
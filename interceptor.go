@@ -0,0 +1,43 @@
+package mazzaroth
+
+import (
+	"net/http"
+)
+
+// RequestInfo describes the Client call a request to a node is made for.
+type RequestInfo struct {
+	// Endpoint is the name of the Client method, e.g. "BlockLookup".
+	Endpoint string
+	// ChannelID is the hex encoded channel the request is made to.
+	ChannelID string
+	// BlockID is the block looked up, if any.
+	BlockID string
+	// BlockHeight is the first height listed by BlockList and
+	// BlockHeaderList.
+	BlockHeight int
+	// TransactionID is the transaction or receipt looked up, if any.
+	TransactionID string
+	// Address is the address of the node the request is made to.
+	Address string
+}
+
+// Invoker sends a request to a node and returns its response.
+type Invoker func(req *http.Request) (*http.Response, error)
+
+// Interceptor wraps every request made by a ClientImpl. It may modify req
+// before passing it on to next, and inspect or replace the response that
+// next returns. An interceptor that does not call next must return either
+// a response or an error itself.
+type Interceptor func(info RequestInfo, req *http.Request, next Invoker) (*http.Response, error)
+
+// chainInterceptors returns an invoker that runs the interceptors in order,
+// the first being the outermost, before calling invoker.
+func chainInterceptors(info RequestInfo, interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(req *http.Request) (*http.Response, error) {
+			return interceptor(info, req, next)
+		}
+	}
+	return invoker
+}
@@ -0,0 +1,58 @@
+package mazzaroth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestInterceptors(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Request-Id")
+		(&xdrNode{}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	var calls []string
+	record := func(name string) Interceptor {
+		return func(info RequestInfo, req *http.Request, next Invoker) (*http.Response, error) {
+			calls = append(calls, name+":"+info.Endpoint+":"+info.ChannelID)
+			req.Header.Set("X-Request-Id", name)
+			return next(req)
+		}
+	}
+
+	client, err := NewMazzarothClient(WithAddress(server.URL), WithInterceptors(record("first"), record("second")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReceiptLookup(context.Background(), "ab", "00"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != 2 || calls[0] != "first:ReceiptLookup:ab" || calls[1] != "second:ReceiptLookup:ab" {
+		t.Fatalf("unexpected interceptor calls: %v", calls)
+	}
+	if header != "second" {
+		t.Fatalf("expected the innermost interceptor's header, got: %s", header)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	errRefused := errors.New("refused")
+	client, err := NewMazzarothClient(WithAddress("http://127.0.0.1:0"), WithInterceptors(
+		func(info RequestInfo, req *http.Request, next Invoker) (*http.Response, error) {
+			return nil, errRefused
+		},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.BlockHeight(context.Background(), "ab"); errors.Cause(err) != errRefused {
+		t.Fatalf("expected the interceptor's error, got: %v", err)
+	}
+}
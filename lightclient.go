@@ -0,0 +1,416 @@
+package mazzaroth
+
+import (
+	"container/list"
+	"context"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/kochavalabs/mazzaroth-go/verify"
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+// defaultSyncBatch is the number of headers requested per BlockHeaderList
+// call while syncing.
+const defaultSyncBatch = 100
+
+// maxLightBlocks is the number of verified blocks a light client remembers
+// the transactions of for receipt lookups.
+const maxLightBlocks = 1024
+
+var _ Client = &LightClient{}
+
+// LightClient wraps a Client talking to an untrusted node. It follows the
+// header chain of a channel from a trusted header, keeps the verified
+// headers in a HeaderStore and checks that the results of lookups are
+// consistent with them before returning them.
+//
+// Blocks and block headers are checked against the trusted header of their
+// height, and against the block id or heights they were requested with.
+// Block ids have to be a height or a hex encoded header hash, other ids can
+// not be verified. Transactions are checked to be signed by their sender and
+// to hash to the id they were looked up with, which makes them match the
+// transaction of that id in any verified block.
+//
+// Receipts are not self verifying, they are checked against the receipts
+// root of the block including their transaction. The light client does not
+// search for that block: receipts can only be looked up for transactions of
+// the last maxLightBlocks blocks fetched through BlockLookup or BlockList of
+// the light client, otherwise ErrUnverifiable is returned. This is a
+// deliberate gap, finding the block of a transaction would take scanning the
+// chain. Callers knowing the height of a transaction, such as indexers,
+// fetch its block first.
+type LightClient struct {
+	client Client
+	store  HeaderStore
+	batch  int
+
+	syncMu sync.Mutex
+
+	// blocks holds the latest maxBlocks verified blocks, least recently
+	// used last, and txs the element of the block including each of their
+	// transactions.
+	mu        sync.Mutex
+	maxBlocks int
+	blocks    *list.List
+	txs       map[string]*list.Element
+}
+
+// lightBlock records a verified block for the transactions it includes.
+type lightBlock struct {
+	channelID string
+	header    xdr.BlockHeader
+	ids       []xdr.ID
+	receipts  map[xdr.ID]*xdr.Receipt
+}
+
+// NewLightClient creates a light client verifying the results of client
+// against the headers in store.
+func NewLightClient(client Client, store HeaderStore) *LightClient {
+	return &LightClient{
+		client:    client,
+		store:     store,
+		batch:     defaultSyncBatch,
+		maxBlocks: maxLightBlocks,
+		blocks:    list.New(),
+		txs:       make(map[string]*list.Element),
+	}
+}
+
+// Trust sets the header a channel is followed from if the store does not
+// hold a trusted header for it yet. The header has to be obtained from a
+// trusted source.
+func (lc *LightClient) Trust(channelID string, header xdr.BlockHeader) error {
+	lc.syncMu.Lock()
+	defer lc.syncMu.Unlock()
+
+	if _, err := lc.store.Latest(channelID); err == nil {
+		return nil
+	} else if err != ErrNotFound {
+		return err
+	}
+	return lc.store.Append(channelID, []xdr.BlockHeader{header})
+}
+
+// Sync follows the header chain of a channel up to the current height of
+// the node, verifying and storing every header. It returns the latest
+// trusted header.
+func (lc *LightClient) Sync(ctx context.Context, channelID string) (*xdr.BlockHeader, error) {
+	lc.syncMu.Lock()
+	defer lc.syncMu.Unlock()
+
+	latest, err := lc.store.Latest(channelID)
+	if err == ErrNotFound {
+		return nil, ErrNoTrustedHeader
+	} else if err != nil {
+		return nil, err
+	}
+
+	height, err := lc.client.BlockHeight(ctx, channelID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get block height")
+	}
+
+	for latest.BlockHeight < height.Height {
+		headers, err := lc.client.BlockHeaderList(ctx, channelID, int(latest.BlockHeight+1), lc.batch)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to list block headers")
+		}
+		if len(headers) == 0 {
+			break
+		}
+		sort.Slice(headers, func(i, j int) bool {
+			return headers[i].BlockHeight < headers[j].BlockHeight
+		})
+
+		if err := verify.HeaderChainFrom(latest, headers); err != nil {
+			return nil, err
+		}
+		if err := lc.store.Append(channelID, headers); err != nil {
+			return nil, errors.Wrap(err, "unable to store block headers")
+		}
+		latest = &headers[len(headers)-1]
+	}
+
+	return latest, nil
+}
+
+// trusted returns the trusted header at height, syncing the channel if the
+// height is beyond the latest trusted header.
+func (lc *LightClient) trusted(ctx context.Context, channelID string, height uint64) (*xdr.BlockHeader, error) {
+	header, err := lc.store.Header(channelID, height)
+	if err != ErrNotFound {
+		return header, err
+	}
+
+	latest, err := lc.Sync(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if height > latest.BlockHeight {
+		return nil, ErrUnverifiable
+	}
+	return lc.store.Header(channelID, height)
+}
+
+// checkHeader checks header against the trusted header of its height.
+func (lc *LightClient) checkHeader(ctx context.Context, channelID string, header *xdr.BlockHeader) error {
+	trusted, err := lc.trusted(ctx, channelID, header.BlockHeight)
+	if err != nil {
+		return err
+	}
+	if *header != *trusted {
+		return &verify.InconsistencyError{Height: header.BlockHeight, Err: ErrUntrustedHeader}
+	}
+	return nil
+}
+
+// checkBlock checks block against the trusted headers and records it.
+func (lc *LightClient) checkBlock(ctx context.Context, channelID string, block *xdr.Block) error {
+	if err := lc.checkHeader(ctx, channelID, &block.Header); err != nil {
+		return err
+	}
+	if err := verify.Block(block); err != nil {
+		return err
+	}
+
+	ids := make([]xdr.ID, len(block.Transactions))
+	for i := range block.Transactions {
+		id, err := verify.TransactionID(&block.Transactions[i])
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	lb := &lightBlock{
+		channelID: channelID,
+		header:    block.Header,
+		ids:       ids,
+		receipts:  make(map[xdr.ID]*xdr.Receipt),
+	}
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	e := lc.blocks.PushFront(lb)
+	for _, id := range ids {
+		lc.txs[blockKey(channelID, id)] = e
+	}
+	for lc.blocks.Len() > lc.maxBlocks {
+		evicted := lc.blocks.Remove(lc.blocks.Back()).(*lightBlock)
+		for _, id := range evicted.ids {
+			key := blockKey(evicted.channelID, id)
+			if lc.txs[key] != nil && lc.txs[key].Value == evicted {
+				delete(lc.txs, key)
+			}
+		}
+	}
+	return nil
+}
+
+// block returns the recorded block that includes the transaction id.
+func (lc *LightClient) block(channelID string, id xdr.ID) *lightBlock {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	e, ok := lc.txs[blockKey(channelID, id)]
+	if !ok {
+		return nil
+	}
+	lc.blocks.MoveToFront(e)
+	return e.Value.(*lightBlock)
+}
+
+func blockKey(channelID string, id xdr.ID) string {
+	return channelID + "/" + hex.EncodeToString(id[:])
+}
+
+// checkBlockID checks that header is the block requested by blockID, either
+// a height or a hex encoded header hash.
+func checkBlockID(blockID string, header *xdr.BlockHeader) error {
+	if height, err := strconv.ParseUint(blockID, 10, 64); err == nil {
+		if header.BlockHeight != height {
+			return &verify.InconsistencyError{Height: header.BlockHeight, Err: ErrUnrequestedBlock}
+		}
+		return nil
+	}
+
+	id, err := xdr.IDFromHexString(blockID)
+	if err != nil {
+		return ErrUnverifiable
+	}
+	hash, err := verify.HeaderHash(header)
+	if err != nil {
+		return err
+	}
+	if hash != xdr.Hash(id) {
+		return &verify.InconsistencyError{Height: header.BlockHeight, Err: ErrUnrequestedBlock}
+	}
+	return nil
+}
+
+// checkListHeights checks that the heights of a list are the number ones
+// requested from blockHeight on, the list ending early at the tip.
+func checkListHeights(blockHeight int, number int, n int, heights func(i int) uint64) error {
+	if n > number {
+		return &verify.InconsistencyError{Height: heights(number), Err: ErrUnrequestedBlock}
+	}
+	for i := 0; i < n; i++ {
+		if heights(i) != uint64(blockHeight+i) {
+			return &verify.InconsistencyError{Height: heights(i), Err: ErrUnrequestedBlock}
+		}
+	}
+	return nil
+}
+
+// BlockHeaderLookup implements Client.
+func (lc *LightClient) BlockHeaderLookup(ctx context.Context, channelID string, blockID string) (*xdr.BlockHeader, error) {
+	header, err := lc.client.BlockHeaderLookup(ctx, channelID, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBlockID(blockID, header); err != nil {
+		return nil, err
+	}
+	if err := lc.checkHeader(ctx, channelID, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// BlockHeaderList implements Client.
+func (lc *LightClient) BlockHeaderList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.BlockHeader, error) {
+	headers, err := lc.client.BlockHeaderList(ctx, channelID, blockHeight, number)
+	if err != nil {
+		return nil, err
+	}
+	heights := func(i int) uint64 { return headers[i].BlockHeight }
+	if err := checkListHeights(blockHeight, number, len(headers), heights); err != nil {
+		return nil, err
+	}
+	for i := range headers {
+		if err := lc.checkHeader(ctx, channelID, &headers[i]); err != nil {
+			return nil, err
+		}
+	}
+	return headers, nil
+}
+
+// BlockHeight implements Client. The height is that of the latest trusted
+// header after syncing the channel.
+func (lc *LightClient) BlockHeight(ctx context.Context, channelID string) (*xdr.BlockHeight, error) {
+	latest, err := lc.Sync(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return &xdr.BlockHeight{Height: latest.BlockHeight}, nil
+}
+
+// BlockLookup implements Client.
+func (lc *LightClient) BlockLookup(ctx context.Context, channelID string, blockID string) (*xdr.Block, error) {
+	block, err := lc.client.BlockLookup(ctx, channelID, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBlockID(blockID, &block.Header); err != nil {
+		return nil, err
+	}
+	if err := lc.checkBlock(ctx, channelID, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// BlockList implements Client.
+func (lc *LightClient) BlockList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.Block, error) {
+	blocks, err := lc.client.BlockList(ctx, channelID, blockHeight, number)
+	if err != nil {
+		return nil, err
+	}
+	heights := func(i int) uint64 { return blocks[i].Header.BlockHeight }
+	if err := checkListHeights(blockHeight, number, len(blocks), heights); err != nil {
+		return nil, err
+	}
+	for i := range blocks {
+		if err := lc.checkBlock(ctx, channelID, &blocks[i]); err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+// ChannelAbi implements Client. The abi is not covered by the block headers
+// and is returned as served by the node.
+func (lc *LightClient) ChannelAbi(ctx context.Context, channelID string) (*xdr.Abi, error) {
+	return lc.client.ChannelAbi(ctx, channelID)
+}
+
+// ReceiptLookup implements Client. All receipts of the block including the
+// transaction are fetched to check them against the receipts root. The
+// block has to be one of the last blocks fetched through the light client,
+// otherwise ErrUnverifiable is returned, see LightClient.
+func (lc *LightClient) ReceiptLookup(ctx context.Context, channelID string, transactionID string) (*xdr.Receipt, error) {
+	id, err := xdr.IDFromHexString(transactionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decode transaction id")
+	}
+	lb := lc.block(channelID, id)
+	if lb == nil {
+		return nil, ErrUnverifiable
+	}
+
+	lc.mu.Lock()
+	receipt, ok := lb.receipts[id]
+	lc.mu.Unlock()
+	if ok {
+		return receipt, nil
+	}
+
+	receipts := make([]xdr.Receipt, len(lb.ids))
+	for i, blockTxID := range lb.ids {
+		r, err := lc.client.ReceiptLookup(ctx, channelID, hex.EncodeToString(blockTxID[:]))
+		if err != nil {
+			return nil, err
+		}
+		if r.TransactionID != blockTxID {
+			return nil, &verify.InconsistencyError{Height: lb.header.BlockHeight, Err: verify.ErrHashMismatch}
+		}
+		receipts[i] = *r
+	}
+	root, err := verify.ReceiptsRoot(receipts)
+	if err != nil {
+		return nil, err
+	}
+	if root != lb.header.TransactionsReceiptRoot {
+		return nil, &verify.InconsistencyError{Height: lb.header.BlockHeight, Err: verify.ErrHashMismatch}
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	for i := range receipts {
+		lb.receipts[receipts[i].TransactionID] = &receipts[i]
+	}
+	return lb.receipts[id], nil
+}
+
+// TransactionLookup implements Client.
+func (lc *LightClient) TransactionLookup(ctx context.Context, channelID string, transactionID string) (*xdr.Transaction, error) {
+	id, err := xdr.IDFromHexString(transactionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decode transaction id")
+	}
+	tx, err := lc.client.TransactionLookup(ctx, channelID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := verify.Transaction(tx, id); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// TransactionSubmit implements Client.
+func (lc *LightClient) TransactionSubmit(ctx context.Context, transaction *xdr.Transaction) (*xdr.ID, *xdr.Receipt, error) {
+	return lc.client.TransactionSubmit(ctx, transaction)
+}
@@ -0,0 +1,341 @@
+package mazzaroth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/kochavalabs/mazzaroth-go/verify"
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+// testNode is an in memory Client serving a single channel.
+type testNode struct {
+	Client
+	blocks   []xdr.Block
+	txs      map[string][2]int
+	receipts map[string]*xdr.Receipt
+}
+
+func newTestNode(t *testing.T, heights int) *testNode {
+	privateKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	sender, err := xdr.IDFromPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &testNode{
+		txs:      make(map[string][2]int),
+		receipts: make(map[string]*xdr.Receipt),
+	}
+	for height := 0; height < heights; height++ {
+		block := xdr.Block{Header: xdr.BlockHeader{BlockHeight: uint64(height), Status: xdr.StatusFINALIZED}}
+		if height > 0 {
+			block.Header.PreviousHeader, err = verify.HeaderHash(&node.blocks[height-1].Header)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var receipts []xdr.Receipt
+		for i := 0; i < 3; i++ {
+			tx, err := Transaction(sender, xdr.ID{}).Call(uint64(height*10+i), 1).Function("test").Sign(privateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id, err := verify.TransactionID(tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block.Transactions = append(block.Transactions, *tx)
+			receipts = append(receipts, xdr.Receipt{TransactionID: id, Status: xdr.StatusSUCCESS})
+			node.txs[hex.EncodeToString(id[:])] = [2]int{height, i}
+			node.receipts[hex.EncodeToString(id[:])] = &receipts[len(receipts)-1]
+		}
+
+		block.Header.TransactionsMerkleRoot, err = verify.TransactionsRoot(block.Transactions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.Header.TransactionsReceiptRoot, err = verify.ReceiptsRoot(receipts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node.blocks = append(node.blocks, block)
+	}
+	return node
+}
+
+func (n *testNode) BlockHeight(ctx context.Context, channelID string) (*xdr.BlockHeight, error) {
+	return &xdr.BlockHeight{Height: uint64(len(n.blocks) - 1)}, nil
+}
+
+func (n *testNode) BlockHeaderList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.BlockHeader, error) {
+	var headers []xdr.BlockHeader
+	for i := blockHeight; i < len(n.blocks) && i < blockHeight+number; i++ {
+		headers = append(headers, n.blocks[i].Header)
+	}
+	return headers, nil
+}
+
+func (n *testNode) BlockHeaderLookup(ctx context.Context, channelID string, blockID string) (*xdr.BlockHeader, error) {
+	block, err := n.BlockLookup(ctx, channelID, blockID)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Header, nil
+}
+
+func (n *testNode) BlockLookup(ctx context.Context, channelID string, blockID string) (*xdr.Block, error) {
+	height, err := strconv.Atoi(blockID)
+	if err != nil || height >= len(n.blocks) {
+		return nil, ErrNotFound
+	}
+	block := n.blocks[height]
+	return &block, nil
+}
+
+func (n *testNode) BlockList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.Block, error) {
+	var blocks []xdr.Block
+	for i := blockHeight; i < len(n.blocks) && i < blockHeight+number; i++ {
+		blocks = append(blocks, n.blocks[i])
+	}
+	return blocks, nil
+}
+
+func (n *testNode) ReceiptLookup(ctx context.Context, channelID string, transactionID string) (*xdr.Receipt, error) {
+	receipt, ok := n.receipts[transactionID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	r := *receipt
+	return &r, nil
+}
+
+func (n *testNode) TransactionLookup(ctx context.Context, channelID string, transactionID string) (*xdr.Transaction, error) {
+	i, ok := n.txs[transactionID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	tx := n.blocks[i[0]].Transactions[i[1]]
+	return &tx, nil
+}
+
+func TestLightClientSync(t *testing.T) {
+	node := newTestNode(t, 250)
+	lc := NewLightClient(node, NewMemoryHeaderStore())
+	ctx := context.Background()
+
+	if _, err := lc.Sync(ctx, "channel"); err != ErrNoTrustedHeader {
+		t.Fatalf("expected no trusted header, got: %v", err)
+	}
+	if err := lc.Trust("channel", node.blocks[0].Header); err != nil {
+		t.Fatal(err)
+	}
+
+	height, err := lc.BlockHeight(ctx, "channel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height.Height != 249 {
+		t.Fatalf("expected height 249, got: %d", height.Height)
+	}
+}
+
+func TestLightClientForkedNode(t *testing.T) {
+	node := newTestNode(t, 10)
+	node.blocks[5].Header.StateRoot[0] = 1
+
+	lc := NewLightClient(node, NewMemoryHeaderStore())
+	if err := lc.Trust("channel", node.blocks[0].Header); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := lc.Sync(context.Background(), "channel")
+	var inconsistent *verify.InconsistencyError
+	if !errors.As(err, &inconsistent) || inconsistent.Height != 6 {
+		t.Fatalf("expected inconsistency at height 6, got: %v", err)
+	}
+}
+
+func TestLightClientLookups(t *testing.T) {
+	node := newTestNode(t, 10)
+	lc := NewLightClient(node, NewMemoryHeaderStore())
+	if err := lc.Trust("channel", node.blocks[0].Header); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := lc.BlockHeaderLookup(ctx, "channel", "4"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lc.BlockList(ctx, "channel", 2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := verify.TransactionID(&node.blocks[7].Transactions[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	txID := hex.EncodeToString(id[:])
+
+	if _, err := lc.ReceiptLookup(ctx, "channel", txID); err != ErrUnverifiable {
+		t.Fatalf("expected unverifiable receipt, got: %v", err)
+	}
+	if _, err := lc.BlockLookup(ctx, "channel", "7"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lc.TransactionLookup(ctx, "channel", txID); err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := lc.ReceiptLookup(ctx, "channel", txID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.TransactionID != id {
+		t.Fatalf("expected receipt of %s, got: %v", txID, receipt)
+	}
+}
+
+func TestLightClientTamperedResults(t *testing.T) {
+	node := newTestNode(t, 10)
+	lc := NewLightClient(node, NewMemoryHeaderStore())
+	if err := lc.Trust("channel", node.blocks[0].Header); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	id, err := verify.TransactionID(&node.blocks[3].Transactions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	txID := hex.EncodeToString(id[:])
+
+	if _, err := lc.BlockLookup(ctx, "channel", "3"); err != nil {
+		t.Fatal(err)
+	}
+	node.receipts[txID].Status = xdr.StatusFAILURE
+	if _, err := lc.ReceiptLookup(ctx, "channel", txID); !errors.Is(err, verify.ErrHashMismatch) {
+		t.Fatalf("expected hash mismatch, got: %v", err)
+	}
+
+	node.blocks[3].Transactions[0].Data.Nonce++
+	if _, err := lc.TransactionLookup(ctx, "channel", txID); err != verify.ErrInvalidSignature {
+		t.Fatalf("expected invalid signature, got: %v", err)
+	}
+	if _, err := lc.BlockLookup(ctx, "channel", "3"); !errors.Is(err, verify.ErrHashMismatch) {
+		t.Fatalf("expected hash mismatch, got: %v", err)
+	}
+
+	node.blocks[4].Header.StateRoot[0] = 1
+	if _, err := lc.BlockHeaderLookup(ctx, "channel", "4"); !errors.Is(err, ErrUntrustedHeader) {
+		t.Fatalf("expected untrusted header, got: %v", err)
+	}
+}
+
+// substitutingNode serves valid blocks other than the requested ones.
+type substitutingNode struct {
+	*testNode
+	shift int
+	extra int
+}
+
+func (n *substitutingNode) BlockHeaderList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.BlockHeader, error) {
+	return n.testNode.BlockHeaderList(ctx, channelID, blockHeight+n.shift, number+n.extra)
+}
+
+func (n *substitutingNode) BlockHeaderLookup(ctx context.Context, channelID string, blockID string) (*xdr.BlockHeader, error) {
+	block, err := n.BlockLookup(ctx, channelID, blockID)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Header, nil
+}
+
+func (n *substitutingNode) BlockLookup(ctx context.Context, channelID string, blockID string) (*xdr.Block, error) {
+	block := n.blocks[1+n.shift]
+	return &block, nil
+}
+
+func (n *substitutingNode) BlockList(ctx context.Context, channelID string, blockHeight int, number int) ([]xdr.Block, error) {
+	return n.testNode.BlockList(ctx, channelID, blockHeight+n.shift, number+n.extra)
+}
+
+func TestLightClientSubstitutedBlocks(t *testing.T) {
+	node := newTestNode(t, 10)
+	hash, err := verify.HeaderHash(&node.blocks[1].Header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		shift     int
+		extra     int
+		lookupErr error
+		listErr   error
+	}{
+		{name: "requested"},
+		{name: "shifted", shift: 1, lookupErr: ErrUnrequestedBlock, listErr: ErrUnrequestedBlock},
+		{name: "extra", extra: 1, listErr: ErrUnrequestedBlock},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lc := NewLightClient(&substitutingNode{testNode: node, shift: test.shift, extra: test.extra}, NewMemoryHeaderStore())
+			if err := lc.Trust("channel", node.blocks[0].Header); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, blockID := range []string{"1", hex.EncodeToString(hash[:])} {
+				if _, err := lc.BlockHeaderLookup(ctx, "channel", blockID); !errors.Is(err, test.lookupErr) {
+					t.Fatalf("expected %v looking up header %s, got: %v", test.lookupErr, blockID, err)
+				}
+				if _, err := lc.BlockLookup(ctx, "channel", blockID); !errors.Is(err, test.lookupErr) {
+					t.Fatalf("expected %v looking up block %s, got: %v", test.lookupErr, blockID, err)
+				}
+			}
+			if _, err := lc.BlockHeaderList(ctx, "channel", 1, 3); !errors.Is(err, test.listErr) {
+				t.Fatalf("expected %v listing headers, got: %v", test.listErr, err)
+			}
+			if _, err := lc.BlockList(ctx, "channel", 1, 3); !errors.Is(err, test.listErr) {
+				t.Fatalf("expected %v listing blocks, got: %v", test.listErr, err)
+			}
+		})
+	}
+
+	lc := NewLightClient(&substitutingNode{testNode: node}, NewMemoryHeaderStore())
+	if _, err := lc.BlockLookup(ctx, "channel", "latest"); err != ErrUnverifiable {
+		t.Fatalf("expected unverifiable block id, got: %v", err)
+	}
+}
+
+func TestLightClientEvictsBlocks(t *testing.T) {
+	node := newTestNode(t, 5)
+	lc := NewLightClient(node, NewMemoryHeaderStore())
+	lc.maxBlocks = 2
+	if err := lc.Trust("channel", node.blocks[0].Header); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := lc.BlockList(ctx, "channel", 1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if lc.blocks.Len() != 2 {
+		t.Fatalf("expected 2 blocks, got: %d", lc.blocks.Len())
+	}
+	for height, verifiable := range map[int]bool{1: false, 2: true, 3: true} {
+		id, err := verify.TransactionID(&node.blocks[height].Transactions[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = lc.ReceiptLookup(ctx, "channel", hex.EncodeToString(id[:]))
+		if verifiable != (err == nil) {
+			t.Fatalf("expected receipt at height %d verifiable %v, got: %v", height, verifiable, err)
+		}
+	}
+}
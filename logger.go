@@ -0,0 +1,47 @@
+package mazzaroth
+
+import (
+	"encoding/hex"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+// Logger receives structured log records as a message followed by
+// alternating keys and values. A *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+// nopLogger discards every record, it is used when no logger is set.
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+
+// loggerOrNop returns logger, or a logger discarding every record if it is
+// nil.
+func loggerOrNop(logger Logger) Logger {
+	if logger == nil {
+		return nopLogger{}
+	}
+	return logger
+}
+
+// logSign logs the outcome of signing a transaction. Only public fields of
+// the transaction are logged, never the key it is signed with.
+func logSign(logger Logger, sender *xdr.ID, category xdr.CategoryType, nonce uint64, err error) {
+	logger = loggerOrNop(logger)
+
+	senderHex := ""
+	if sender != nil {
+		senderHex = hex.EncodeToString(sender[:])
+	}
+	if err != nil {
+		logger.Warn("unable to sign transaction", "sender", senderHex, "category", category.String(), "nonce", nonce, "error", err)
+		return
+	}
+	logger.Info("signed transaction", "sender", senderHex, "category", category.String(), "nonce", nonce)
+}
@@ -0,0 +1,95 @@
+package mazzaroth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+type logRecord struct {
+	level string
+	msg   string
+	args  map[string]interface{}
+}
+
+type recordLogger struct {
+	records []logRecord
+}
+
+func (l *recordLogger) record(level, msg string, args []interface{}) {
+	r := logRecord{level: level, msg: msg, args: map[string]interface{}{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		r.args[args[i].(string)] = args[i+1]
+	}
+	l.records = append(l.records, r)
+}
+
+func (l *recordLogger) Debug(msg string, args ...interface{}) { l.record("debug", msg, args) }
+func (l *recordLogger) Info(msg string, args ...interface{})  { l.record("info", msg, args) }
+func (l *recordLogger) Warn(msg string, args ...interface{})  { l.record("warn", msg, args) }
+
+func TestClientLogger(t *testing.T) {
+	server := httptest.NewServer(&xdrNode{})
+	defer server.Close()
+
+	logger := &recordLogger{}
+	client, err := NewMazzarothClient(WithAddress(server.URL), WithEncoding(EncodingXDR), WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReceiptLookup(context.Background(), "ab", "01"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the refused xdr request, the fallback and the json request
+	if len(logger.records) != 3 {
+		t.Fatalf("expected 3 records, got: %v", logger.records)
+	}
+	levels := []string{"warn", "info", "debug"}
+	for i, r := range logger.records {
+		if r.level != levels[i] {
+			t.Errorf("expected record %d to be %s, got: %s", i, levels[i], r.level)
+		}
+	}
+	last := logger.records[2].args
+	if last["endpoint"] != "ReceiptLookup" || last["status"] != 200 || last["attempt"] != 2 || last["bytes"].(int) == 0 {
+		t.Errorf("unexpected request record: %v", last)
+	}
+}
+
+func TestBuilderLogger(t *testing.T) {
+	seed, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000000")
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	sender, err := xdr.IDFromPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &recordLogger{}
+	txb := Transaction(sender, xdr.ID{}).Logger(logger)
+	if _, err := txb.Call(7, 1).Function("test").Sign(privateKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txb.Call(8, 1).Sign(privateKey); err == nil {
+		t.Fatal("expected signing without a function to fail")
+	}
+
+	if len(logger.records) != 2 || logger.records[0].level != "info" || logger.records[1].level != "warn" {
+		t.Fatalf("unexpected records: %v", logger.records)
+	}
+	signed := logger.records[0].args
+	if signed["sender"] != hex.EncodeToString(sender[:]) || signed["category"] != "CategoryTypeCALL" || signed["nonce"] != uint64(7) {
+		t.Errorf("unexpected sign record: %v", signed)
+	}
+	for _, r := range logger.records {
+		if strings.Contains(fmt.Sprint(r.args), hex.EncodeToString(privateKey)) {
+			t.Errorf("private key logged in: %v", r)
+		}
+	}
+}
@@ -1,14 +1,37 @@
 package mazzaroth
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
 	"net/http"
 	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // mazzarothOptions config options for client
 type mazzarothClientOptions struct {
-	httpClient *http.Client
-	address    string
+	httpClient   *http.Client
+	addresses    []string
+	encoding     Encoding
+	interceptors []Interceptor
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+
+	logger Logger
+
+	readLimit   rateLimit
+	submitLimit rateLimit
+
+	circuit    circuitConfig
+	hedgeDelay time.Duration
+	health     healthConfig
+
+	tls  tlsOptions
+	auth authOptions
 }
 
 // Options interface for applying service options
@@ -42,7 +65,201 @@ func WithHttpClient(client *http.Client) Options {
 // WithAddress used to set the http client that the mazzaroth client should use
 func WithAddress(address string) Options {
 	return newFuncPacketOption(func(o *mazzarothClientOptions) {
-		o.address = address
+		o.addresses = []string{address}
+	})
+}
+
+// WithEncoding used to set the wire format the mazzaroth client should use,
+// falling back to json for each node that refuses EncodingXDR
+func WithEncoding(encoding Encoding) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.encoding = encoding
+	})
+}
+
+// WithInterceptors used to add interceptors that wrap every request the
+// mazzaroth client makes, run in the order given
+func WithInterceptors(interceptors ...Interceptor) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	})
+}
+
+// WithTracerProvider used to set the provider of the tracer creating a span
+// for each call the mazzaroth client makes, the global provider by default
+func WithTracerProvider(provider trace.TracerProvider) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.tracerProvider = provider
+	})
+}
+
+// WithMeterProvider used to set the provider of the meter recording latency,
+// response sizes and errors of the mazzaroth client, the global provider by
+// default
+func WithMeterProvider(provider metric.MeterProvider) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.meterProvider = provider
+	})
+}
+
+// WithLogger used to set the logger that the mazzaroth client logs every
+// request to
+func WithLogger(logger Logger) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.logger = logger
+	})
+}
+
+// WithRateLimit used to limit the requests the mazzaroth client makes to a
+// node to rps per second, allowing bursts of burst requests. Reads and
+// transaction submissions are limited separately, see WithSubmitRateLimit
+func WithRateLimit(rps float64, burst int) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.readLimit.rps, o.readLimit.burst = rps, burst
+		o.submitLimit.rps, o.submitLimit.burst = rps, burst
+	})
+}
+
+// WithMaxInFlight used to limit the requests the mazzaroth client has in
+// flight to a node at once to n. Reads and transaction submissions are
+// limited separately, see WithSubmitMaxInFlight
+func WithMaxInFlight(n int) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.readLimit.inFlight = n
+		o.submitLimit.inFlight = n
+	})
+}
+
+// WithSubmitRateLimit used to set a rate limit for transaction submissions
+// different from the one set by WithRateLimit
+func WithSubmitRateLimit(rps float64, burst int) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.submitLimit.rps, o.submitLimit.burst = rps, burst
+	})
+}
+
+// WithSubmitMaxInFlight used to set a limit of transaction submissions in
+// flight different from the one set by WithMaxInFlight
+func WithSubmitMaxInFlight(n int) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.submitLimit.inFlight = n
+	})
+}
+
+// WithAddresses used to set several nodes that the mazzaroth client spreads
+// its requests over
+func WithAddresses(addresses ...string) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.addresses = addresses
+	})
+}
+
+// WithCircuitBreaker used to stop making requests to a node once ratio of
+// at least minRequests requests to it failed, until a probe after
+// openTimeout succeeds. Requests are failed with ErrCircuitOpen while the
+// breakers of all nodes are open
+func WithCircuitBreaker(ratio float64, minRequests int, openTimeout time.Duration) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.circuit.ratio = ratio
+		o.circuit.minRequests = minRequests
+		o.circuit.openTimeout = openTimeout
+	})
+}
+
+// WithCircuitStateFunc used to set a function called whenever the circuit
+// breaker of a node changes state
+func WithCircuitStateFunc(f CircuitStateFunc) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.circuit.onChange = f
+	})
+}
+
+// WithHedging used to make read requests that a node did not answer after
+// delay to a second node as well, returning whichever answers first
+func WithHedging(delay time.Duration) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.hedgeDelay = delay
+	})
+}
+
+// WithHealthCheck used to set the channel whose block height health checks
+// probe nodes with, and how many blocks a node may be behind the highest
+// before it stops serving reads
+func WithHealthCheck(channelID string, maxLag uint64) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.health = healthConfig{channelID: channelID, maxLag: maxLag}
+	})
+}
+
+// WithClientCertificate used to set a certificate the mazzaroth client
+// presents to nodes requiring mutual tls
+func WithClientCertificate(cert tls.Certificate) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.tls.certificates = append(o.tls.certificates, cert)
+	})
+}
+
+// WithClientCertificateFiles used to set a certificate the mazzaroth client
+// presents to nodes requiring mutual tls, loaded from a pair of PEM files
+func WithClientCertificateFiles(certFile, keyFile string) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.tls.certFiles = append(o.tls.certFiles, [2]string{certFile, keyFile})
+	})
+}
+
+// WithRootCAFiles used to set the PEM files of the certificate authorities
+// the mazzaroth client trusts instead of the system ones
+func WithRootCAFiles(files ...string) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.tls.caFiles = append(o.tls.caFiles, files...)
+	})
+}
+
+// WithServerName used to set the name the mazzaroth client verifies node
+// certificates against instead of the host of their address
+func WithServerName(name string) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.tls.serverName = name
+	})
+}
+
+// WithMinTLSVersion used to set the minimum tls version the mazzaroth client
+// accepts, e.g. tls.VersionTLS13. TLS 1.2 is required by default once any
+// tls option is given
+func WithMinTLSVersion(version uint16) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.tls.minVersion = version
+	})
+}
+
+// WithBearerToken used to send a static bearer token with every request
+func WithBearerToken(token string) Options {
+	return WithTokenSource(TokenSourceFunc(func(ctx context.Context) (string, error) {
+		return token, nil
+	}))
+}
+
+// WithTokenSource used to send a bearer token from source with every
+// request, see NewRefreshingTokenSource for tokens that expire
+func WithTokenSource(source TokenSource) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.auth.tokens = source
+	})
+}
+
+// WithAPIKey used to send an api key in header with every request
+func WithAPIKey(header, key string) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.auth.apiKeyHeader = header
+		o.auth.apiKey = key
+	})
+}
+
+// WithRequestSigning used to sign the method, path, timestamp and body of
+// every request with an account key, see VerifyRequest
+func WithRequestSigning(key ed25519.PrivateKey) Options {
+	return newFuncPacketOption(func(o *mazzarothClientOptions) {
+		o.auth.signingKey = key
 	})
 }
 
@@ -52,6 +269,7 @@ func defaultOption() *mazzarothClientOptions {
 		httpClient: &http.Client{
 			Timeout: 500 * time.Millisecond,
 		},
-		address: "http://localhost:6299",
+		addresses: []string{"http://localhost:6299"},
+		encoding:  EncodingJSON,
 	}
 }
@@ -0,0 +1,168 @@
+package mazzaroth
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRateLimitRetries is the number of times a request refused with
+	// http.StatusTooManyRequests is retried.
+	maxRateLimitRetries = 3
+	// rateLimitBackoff is the first backoff after a node refused a request
+	// without a Retry-After header, it doubles on every retry.
+	rateLimitBackoff = 500 * time.Millisecond
+)
+
+// rateLimit is the configuration of a budget.
+type rateLimit struct {
+	rps      float64
+	burst    int
+	inFlight int
+}
+
+// rateLimits hands out the budgets of every node a client talks to. Reads
+// and transaction submissions are budgeted separately.
+type rateLimits struct {
+	read   rateLimit
+	submit rateLimit
+
+	mu    sync.Mutex
+	nodes map[string]*nodeBudgets
+}
+
+type nodeBudgets struct {
+	read   *budget
+	submit *budget
+}
+
+func newRateLimits(read, submit rateLimit) *rateLimits {
+	return &rateLimits{
+		read:   read,
+		submit: submit,
+		nodes:  make(map[string]*nodeBudgets),
+	}
+}
+
+// budget returns the budget for requests to the node at address.
+func (l *rateLimits) budget(address string, submit bool) *budget {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	node, ok := l.nodes[address]
+	if !ok {
+		node = &nodeBudgets{read: newBudget(l.read), submit: newBudget(l.submit)}
+		l.nodes[address] = node
+	}
+	if submit {
+		return node.submit
+	}
+	return node.read
+}
+
+// budget is a token bucket refilled at a fixed rate combined with a cap on
+// the number of requests in flight. A budget without a rate or cap does not
+// limit requests, but still holds them back after a node asked to back off.
+type budget struct {
+	rate     float64
+	burst    float64
+	inFlight chan struct{}
+
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+func newBudget(limit rateLimit) *budget {
+	b := &budget{
+		rate:  limit.rps,
+		burst: math.Max(float64(limit.burst), 1),
+		last:  time.Now(),
+	}
+	b.tokens = b.burst
+	if limit.inFlight > 0 {
+		b.inFlight = make(chan struct{}, limit.inFlight)
+	}
+	return b
+}
+
+// acquire waits until a request may be made and returns a function that
+// has to be called once it is done.
+func (b *budget) acquire(ctx context.Context) (func(), error) {
+	release := func() {}
+	if b.inFlight != nil {
+		select {
+		case b.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-b.inFlight }
+	}
+
+	for {
+		wait := b.reserve()
+		if wait == 0 {
+			return release, nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token if one is available, otherwise it returns how long
+// to wait before trying again.
+func (b *budget) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(b.blockedUntil) {
+		return b.blockedUntil.Sub(now)
+	}
+	if b.rate <= 0 {
+		return 0
+	}
+
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// backoff holds back every request of the budget for d.
+func (b *budget) backoff(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+}
+
+// retryAfter returns how long to back off after the retry-th refused
+// request, as asked by the node's Retry-After header if it sent one.
+func retryAfter(header http.Header, retry int) time.Duration {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if t, err := http.ParseTime(value); err == nil {
+			return time.Until(t)
+		}
+	}
+	return rateLimitBackoff << retry
+}
@@ -0,0 +1,137 @@
+package mazzaroth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(&xdrNode{})
+	defer server.Close()
+
+	client, err := NewMazzarothClient(WithAddress(server.URL), WithRateLimit(20, 2), WithSubmitRateLimit(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	begin := time.Now()
+	for i := 0; i < 6; i++ {
+		if _, err := client.ReceiptLookup(ctx, "ab", "01"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a burst of 2 and 4 requests at 20 per second
+	if elapsed := time.Since(begin); elapsed < 190*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("expected 6 reads to take about 200ms, took: %s", elapsed)
+	}
+
+	// submissions have their own budget
+	begin = time.Now()
+	tx := &xdr.Transaction{Data: xdr.Data{Category: xdr.Category{Type: xdr.CategoryTypeDELETE}}}
+	if _, _, err := client.TransactionSubmit(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(begin); elapsed > 100*time.Millisecond {
+		t.Fatalf("expected the submission not to wait for reads, took: %s", elapsed)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, _, err := client.TransactionSubmit(timeout, tx); err == nil {
+		t.Fatal("expected the second submission to time out waiting for the rate limit")
+	}
+}
+
+func TestMaxInFlight(t *testing.T) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&peak)
+			if n <= m || atomic.CompareAndSwapInt32(&peak, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		(&xdrNode{}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client, err := NewMazzarothClient(WithAddress(server.URL), WithMaxInFlight(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.ReceiptLookup(context.Background(), "ab", "01"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak != 2 {
+		t.Fatalf("expected at most 2 requests in flight, got: %d", peak)
+	}
+}
+
+func TestTooManyRequests(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		(&xdrNode{}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client, err := NewMazzarothClient(WithAddress(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReceiptLookup(context.Background(), "ab", "01"); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Fatalf("expected 3 requests, got: %d", requests)
+	}
+
+	// a node that keeps refusing gets the error eventually
+	atomic.StoreInt32(&requests, -10)
+	if _, err := client.ReceiptLookup(context.Background(), "ab", "01"); err == nil {
+		t.Fatal("expected too many requests to fail")
+	}
+	if requests != -10+maxRateLimitRetries+1 {
+		t.Fatalf("expected %d requests, got: %d", maxRateLimitRetries+1, requests+10)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	header := http.Header{}
+	if d := retryAfter(header, 2); d != 4*rateLimitBackoff {
+		t.Fatalf("expected exponential backoff, got: %s", d)
+	}
+	header.Set("Retry-After", "3")
+	if d := retryAfter(header, 2); d != 3*time.Second {
+		t.Fatalf("expected 3s, got: %s", d)
+	}
+	header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if d := retryAfter(header, 0); d < 58*time.Second || d > time.Minute {
+		t.Fatalf("expected about a minute, got: %s", d)
+	}
+}
@@ -0,0 +1,100 @@
+package mazzaroth
+
+import (
+	"sync/atomic"
+)
+
+// node is a node the client makes requests to.
+type node struct {
+	address string
+	breaker *circuitBreaker
+	// unhealthy is set atomically by health checks finding the node down or
+	// lagging behind the others.
+	unhealthy int32
+	// encoding is accessed atomically since it falls back to json once the
+	// node refuses xdr.
+	encoding int32
+}
+
+func (n *node) healthy() bool {
+	return atomic.LoadInt32(&n.unhealthy) == 0
+}
+
+func (n *node) currentEncoding() Encoding {
+	return Encoding(atomic.LoadInt32(&n.encoding))
+}
+
+func (n *node) setEncoding(encoding Encoding) {
+	atomic.StoreInt32(&n.encoding, int32(encoding))
+}
+
+func (n *node) setHealthy(healthy bool) {
+	var unhealthy int32
+	if !healthy {
+		unhealthy = 1
+	}
+	atomic.StoreInt32(&n.unhealthy, unhealthy)
+}
+
+// serverSelector spreads requests over nodes round robin, skipping nodes
+// whose circuit breaker is open. Reads also skip nodes the last health check
+// found unhealthy, unless no healthy node is available.
+type serverSelector struct {
+	nodes []*node
+	next  uint32
+}
+
+func newServerSelector(addresses []string, config circuitConfig, encoding Encoding) (*serverSelector, error) {
+	if len(addresses) == 0 {
+		return nil, ErrEmptyServerList
+	}
+	s := &serverSelector{}
+	for _, address := range addresses {
+		s.nodes = append(s.nodes, &node{address: address, breaker: newCircuitBreaker(address, config), encoding: int32(encoding)})
+	}
+	return s, nil
+}
+
+// ordered returns the nodes in the order the next request tries them,
+// healthy nodes first for reads. Circuit breakers are not consulted.
+func (s *serverSelector) ordered(read bool) []*node {
+	start := atomic.AddUint32(&s.next, 1) - 1
+	nodes := make([]*node, 0, len(s.nodes))
+	var unhealthy []*node
+	for i := range s.nodes {
+		n := s.nodes[(int(start)+i)%len(s.nodes)]
+		if read && !n.healthy() {
+			unhealthy = append(unhealthy, n)
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	return append(nodes, unhealthy...)
+}
+
+// selectNode returns the next node a request may be made to, or
+// ErrCircuitOpen if the breakers of all nodes are open.
+func (s *serverSelector) selectNode(read bool) (*node, error) {
+	return s.selectOther(nil, read)
+}
+
+// selectOther returns the next node other than exclude that a request may
+// be made to.
+func (s *serverSelector) selectOther(exclude *node, read bool) (*node, error) {
+	start := atomic.AddUint32(&s.next, 1) - 1
+	for _, healthyOnly := range []bool{read, false} {
+		for i := range s.nodes {
+			n := s.nodes[(int(start)+i)%len(s.nodes)]
+			if n == exclude || (healthyOnly && !n.healthy()) {
+				continue
+			}
+			if n.breaker.allow() {
+				return n, nil
+			}
+		}
+		if !healthyOnly {
+			break
+		}
+	}
+	return nil, ErrCircuitOpen
+}
@@ -0,0 +1,169 @@
+package mazzaroth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+// session tracks the block heights a session client observed per channel,
+// and the heights its nodes were last seen at.
+type session struct {
+	mu       sync.Mutex
+	observed map[string]uint64
+	nodes    map[*node]map[string]uint64
+}
+
+func newSession() *session {
+	return &session{
+		observed: make(map[string]uint64),
+		nodes:    make(map[*node]map[string]uint64),
+	}
+}
+
+// Session returns a client sharing the nodes and configuration of c whose
+// reads are consistent: every read is made to a node whose height on the
+// channel is at least the highest height the session observed, trying the
+// other nodes if the next one is behind. Heights are observed from block
+// heights, blocks and block headers returned. Submitting a transaction
+// raises the observed height to the height of the node it was submitted to,
+// past it if the node answered without a receipt, so the session reads its
+// own writes once a node included the transaction. ErrNoConsistentNode is
+// returned if no node is far enough.
+func (c *ClientImpl) Session() *ClientImpl {
+	s := &ClientImpl{
+		httpClient:   c.httpClient,
+		nodes:        c.nodes,
+		interceptors: c.interceptors,
+		telemetry:    c.telemetry,
+		logger:       c.logger,
+		limits:       c.limits,
+		hedgeDelay:   c.hedgeDelay,
+		health:       c.health,
+		auth:         c.auth,
+		session:      newSession(),
+	}
+	return s
+}
+
+// ObservedHeight returns the highest block height of a channel the session
+// observed, zero for clients that are not sessions.
+func (c *ClientImpl) ObservedHeight(channelID string) uint64 {
+	if c.session == nil {
+		return 0
+	}
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+	return c.session.observed[channelID]
+}
+
+// observe raises the observed height of a channel and the height node was
+// seen at to height.
+func (s *session) observe(n *node, channelID string, height uint64) {
+	s.require(channelID, height)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	heights, ok := s.nodes[n]
+	if !ok {
+		heights = make(map[string]uint64)
+		s.nodes[n] = heights
+	}
+	if height > heights[channelID] {
+		heights[channelID] = height
+	}
+}
+
+// require raises the observed height of a channel to height.
+func (s *session) require(channelID string, height uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if height > s.observed[channelID] {
+		s.observed[channelID] = height
+	}
+}
+
+// behind reports whether node was last seen below the observed height of a
+// channel.
+func (s *session) behind(n *node, channelID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nodes[n][channelID] < s.observed[channelID]
+}
+
+// responseHeight returns the highest block height revealed by a response.
+func responseHeight(xdrResp *xdr.Response) (uint64, bool) {
+	switch {
+	case xdrResp.Height != nil:
+		return xdrResp.Height.Height, true
+	case xdrResp.Block != nil:
+		return xdrResp.Block.Header.BlockHeight, true
+	case xdrResp.BlockHeader != nil:
+		return xdrResp.BlockHeader.BlockHeight, true
+	case xdrResp.Blocks != nil && len(*xdrResp.Blocks) > 0:
+		blocks := *xdrResp.Blocks
+		return blocks[len(blocks)-1].Header.BlockHeight, true
+	case xdrResp.BlockHeaders != nil && len(*xdrResp.BlockHeaders) > 0:
+		headers := *xdrResp.BlockHeaders
+		return headers[len(headers)-1].BlockHeight, true
+	}
+	return 0, false
+}
+
+// nodeHeight asks node for its block height on a channel and records it.
+func (c *ClientImpl) nodeHeight(ctx context.Context, n *node, channelID string) (uint64, error) {
+	info := RequestInfo{Endpoint: "BlockHeight", ChannelID: channelID}
+	path := "/" + version + "/channels/" + channelID + "/blocks/height"
+	xdrResp, _, _, err := c.probeNode(ctx, n, info, path, http.MethodGet, nil)
+	if err != nil {
+		return 0, err
+	}
+	if xdrResp.Height == nil {
+		return 0, errors.New("missing block height")
+	}
+	c.session.observe(n, channelID, xdrResp.Height.Height)
+	return xdrResp.Height.Height, nil
+}
+
+// sessionRead makes a read request to the first node that reached the
+// observed height of the channel.
+func (c *ClientImpl) sessionRead(ctx context.Context, info RequestInfo, path string, method string, body interface{}) (*node, *xdr.Response, int, int, error) {
+	for _, n := range c.nodes.ordered(true) {
+		if c.session.behind(n, info.ChannelID) {
+			if _, err := c.nodeHeight(ctx, n, info.ChannelID); err != nil || c.session.behind(n, info.ChannelID) {
+				c.logger.Debug("skipping node behind the session", "address", n.address, "channel", info.ChannelID)
+				continue
+			}
+		}
+		if !n.breaker.allow() {
+			continue
+		}
+
+		xdrResp, status, size, err := c.doNode(ctx, n, info, path, method, body)
+		return n, xdrResp, status, size, err
+	}
+	return nil, nil, 0, 0, ErrNoConsistentNode
+}
+
+// sessionObserve records the heights revealed by the response of node.
+func (c *ClientImpl) sessionObserve(ctx context.Context, n *node, info RequestInfo, xdrResp *xdr.Response) {
+	if info.Endpoint == "TransactionSubmit" {
+		height, err := c.nodeHeight(ctx, n, info.ChannelID)
+		if err != nil {
+			c.logger.Warn("unable to observe the height after submitting", "address", n.address, "error", err)
+			return
+		}
+		// a receipt means the node already included the transaction,
+		// otherwise it includes it from its next height on
+		if xdrResp.Receipt == nil {
+			c.session.require(info.ChannelID, height+1)
+		}
+		return
+	}
+	if height, ok := responseHeight(xdrResp); ok {
+		c.session.observe(n, info.ChannelID, height)
+	}
+}
@@ -0,0 +1,180 @@
+package mazzaroth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+// sessionNode serves its block height and blocks of any channel. Submitted
+// transactions are included at the next height once include is called, or
+// right away answering with a receipt for nodes that execute on submit.
+type sessionNode struct {
+	height   uint64
+	pending  uint64
+	lookups  int32
+	executes bool
+}
+
+// include moves the node to the height including its pending transactions.
+func (n *sessionNode) include() {
+	if atomic.SwapUint64(&n.pending, 0) > 0 {
+		atomic.AddUint64(&n.height, 1)
+	}
+}
+
+func (n *sessionNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var resp xdr.Response
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/blocks/height"):
+		resp, _ = xdr.NewResponse(xdr.ResponseTypeHEIGHT, xdr.BlockHeight{Height: atomic.LoadUint64(&n.height)})
+	case r.Method == http.MethodPost && n.executes:
+		atomic.AddUint64(&n.height, 1)
+		resp, _ = xdr.NewResponse(xdr.ResponseTypeRECEIPT, xdr.Receipt{Status: xdr.StatusSUCCESS})
+	case r.Method == http.MethodPost:
+		atomic.AddUint64(&n.pending, 1)
+		resp, _ = xdr.NewResponse(xdr.ResponseTypeTRANSACTIONID, xdr.ID{})
+	default:
+		atomic.AddInt32(&n.lookups, 1)
+		header := xdr.BlockHeader{BlockHeight: atomic.LoadUint64(&n.height)}
+		resp, _ = xdr.NewResponse(xdr.ResponseTypeBLOCK, xdr.Block{Header: header})
+	}
+	b, _ := resp.MarshalJSON()
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.Write(b)
+}
+
+func newSessionNodes(t *testing.T, heights ...uint64) ([]*sessionNode, *ClientImpl) {
+	var nodes []*sessionNode
+	var addresses []string
+	for _, height := range heights {
+		n := &sessionNode{height: height}
+		server := httptest.NewServer(n)
+		t.Cleanup(server.Close)
+		nodes = append(nodes, n)
+		addresses = append(addresses, server.URL)
+	}
+	client, err := NewMazzarothClient(WithAddresses(addresses...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nodes, client
+}
+
+func TestSession(t *testing.T) {
+	nodes, client := newSessionNodes(t, 5, 10)
+	behind := nodes[0]
+	session := client.Session()
+	ctx := context.Background()
+
+	// heights are observed from whichever node answers
+	for session.ObservedHeight("ab") < 10 {
+		if _, err := session.BlockHeight(ctx, "ab"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		block, err := session.BlockLookup(ctx, "ab", "latest")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block.Header.BlockHeight != 10 {
+			t.Fatalf("expected reads from the node at height 10, got: %d", block.Header.BlockHeight)
+		}
+	}
+	if atomic.LoadInt32(&behind.lookups) != 0 {
+		t.Fatal("expected no reads from the node behind")
+	}
+
+	// the node behind serves reads again once it caught up
+	atomic.StoreUint64(&behind.height, 10)
+	for i := 0; i < 4; i++ {
+		if _, err := session.BlockLookup(ctx, "ab", "latest"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if atomic.LoadInt32(&behind.lookups) == 0 {
+		t.Fatal("expected reads from the node that caught up")
+	}
+
+	// the client itself is not a session
+	if client.ObservedHeight("ab") != 0 {
+		t.Fatal("expected the client not to observe heights")
+	}
+}
+
+func TestSessionReadYourWrites(t *testing.T) {
+	nodes, client := newSessionNodes(t, 3, 3)
+	session := client.Session()
+	ctx := context.Background()
+
+	tx := &xdr.Transaction{Data: xdr.Data{Category: xdr.Category{Type: xdr.CategoryTypeDELETE}}}
+	if _, _, err := session.TransactionSubmit(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	channelID := strings.Repeat("00", 32)
+	if session.ObservedHeight(channelID) != 4 {
+		t.Fatalf("expected the height after the submission, got: %d", session.ObservedHeight(channelID))
+	}
+
+	// no node included the transaction yet
+	if _, err := session.BlockLookup(ctx, channelID, "latest"); !errors.Is(err, ErrNoConsistentNode) {
+		t.Fatalf("expected no consistent node before the inclusion, got: %v", err)
+	}
+	for _, n := range nodes {
+		n.include()
+	}
+
+	// only the node the transaction was submitted to has it
+	for i := 0; i < 4; i++ {
+		block, err := session.BlockLookup(ctx, channelID, "latest")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block.Header.BlockHeight != 4 {
+			t.Fatalf("expected to read the write, got height: %d", block.Header.BlockHeight)
+		}
+	}
+	if atomic.LoadInt32(&nodes[0].lookups) != 0 && atomic.LoadInt32(&nodes[1].lookups) != 0 {
+		t.Fatal("expected reads from a single node")
+	}
+
+	// no node reached a height observed elsewhere
+	session.session.observe(nil, channelID, 100)
+	if _, err := session.BlockLookup(ctx, channelID, "latest"); !errors.Is(err, ErrNoConsistentNode) {
+		t.Fatalf("expected no consistent node, got: %v", err)
+	}
+}
+
+func TestSessionReadYourExecutedWrites(t *testing.T) {
+	nodes, client := newSessionNodes(t, 3, 3)
+	for _, n := range nodes {
+		n.executes = true
+	}
+	session := client.Session()
+	ctx := context.Background()
+
+	tx := &xdr.Transaction{Data: xdr.Data{Category: xdr.Category{Type: xdr.CategoryTypeDELETE}}}
+	if _, _, err := session.TransactionSubmit(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	channelID := strings.Repeat("00", 32)
+	if session.ObservedHeight(channelID) != 4 {
+		t.Fatalf("expected the height including the transaction, got: %d", session.ObservedHeight(channelID))
+	}
+
+	// the node answering with a receipt serves reads right away
+	block, err := session.BlockLookup(ctx, channelID, "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Header.BlockHeight != 4 {
+		t.Fatalf("expected to read the write, got height: %d", block.Header.BlockHeight)
+	}
+}
@@ -0,0 +1,142 @@
+package mazzaroth
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/kochavalabs/mazzaroth-go"
+
+// Attribute keys set on the spans and metrics of the client.
+const (
+	attributeEndpoint      = attribute.Key("mazzaroth.endpoint")
+	attributeChannelID     = attribute.Key("mazzaroth.channel_id")
+	attributeBlockID       = attribute.Key("mazzaroth.block_id")
+	attributeBlockHeight   = attribute.Key("mazzaroth.block_height")
+	attributeTransactionID = attribute.Key("mazzaroth.transaction_id")
+	attributeMethod        = attribute.Key("http.request.method")
+	attributeStatusCode    = attribute.Key("http.response.status_code")
+	attributeResponseSize  = attribute.Key("http.response.body.size")
+	attributeServerAddress = attribute.Key("server.address")
+)
+
+// telemetry creates the spans and records the metrics of a ClientImpl.
+type telemetry struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	duration   metric.Float64Histogram
+	size       metric.Int64Histogram
+	errors     metric.Int64Counter
+}
+
+// newTelemetry creates the instruments of a client, using the global
+// providers for those that are nil.
+func newTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (*telemetry, error) {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+	meter := meterProvider.Meter(instrumentationName)
+
+	duration, err := meter.Float64Histogram("mazzaroth.client.duration",
+		metric.WithDescription("Duration of the calls made to a node."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create duration histogram")
+	}
+	size, err := meter.Int64Histogram("mazzaroth.client.response.size",
+		metric.WithDescription("Size of the response bodies returned by a node."),
+		metric.WithUnit("By"))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create response size histogram")
+	}
+	errs, err := meter.Int64Counter("mazzaroth.client.errors",
+		metric.WithDescription("Number of calls made to a node that failed."))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create error counter")
+	}
+
+	return &telemetry{
+		tracer:     tracerProvider.Tracer(instrumentationName),
+		propagator: otel.GetTextMapPropagator(),
+		duration:   duration,
+		size:       size,
+		errors:     errs,
+	}, nil
+}
+
+// start starts the span of a call described by info. The returned function
+// ends it and records the call's metrics, given the address of the node the
+// call was made to.
+func (t *telemetry) start(ctx context.Context, info RequestInfo, method string) (context.Context, func(string, *xdr.Response, int, int, error)) {
+	begin := time.Now()
+
+	attributes := []attribute.KeyValue{
+		attributeEndpoint.String(info.Endpoint),
+		attributeChannelID.String(info.ChannelID),
+		attributeMethod.String(method),
+	}
+	if info.BlockID != "" {
+		attributes = append(attributes, attributeBlockID.String(info.BlockID))
+	}
+	if info.Endpoint == "BlockList" || info.Endpoint == "BlockHeaderList" {
+		attributes = append(attributes, attributeBlockHeight.Int(info.BlockHeight))
+	}
+	if info.TransactionID != "" {
+		attributes = append(attributes, attributeTransactionID.String(info.TransactionID))
+	}
+
+	ctx, span := t.tracer.Start(ctx, info.Endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...))
+
+	return ctx, func(address string, xdrResp *xdr.Response, status int, size int, err error) {
+		span.SetAttributes(attributeServerAddress.String(address))
+		if info.TransactionID == "" && xdrResp != nil {
+			// a submitted transaction's id is only known once the node answers
+			if xdrResp.TransactionID != nil {
+				span.SetAttributes(attributeTransactionID.String(hex.EncodeToString(xdrResp.TransactionID[:])))
+			} else if xdrResp.Receipt != nil {
+				span.SetAttributes(attributeTransactionID.String(hex.EncodeToString(xdrResp.Receipt.TransactionID[:])))
+			}
+		}
+		if status != 0 {
+			span.SetAttributes(attributeStatusCode.Int(status))
+		}
+		span.SetAttributes(attributeResponseSize.Int(size))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		measured := metric.WithAttributes(
+			attributeEndpoint.String(info.Endpoint),
+			attributeServerAddress.String(address),
+			attributeStatusCode.Int(status),
+		)
+		t.duration.Record(ctx, time.Since(begin).Seconds(), measured)
+		t.size.Record(ctx, int64(size), measured)
+		if err != nil {
+			t.errors.Add(ctx, 1, measured)
+		}
+	}
+}
+
+// inject propagates the trace context of ctx into the headers of a request.
+func (t *telemetry) inject(ctx context.Context, header http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,88 @@
+package mazzaroth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTelemetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/channels/ab/blocks/height" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		(&xdrNode{}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	client, err := NewMazzarothClient(WithAddress(server.URL),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ReceiptLookup(context.Background(), "ab", "01"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.BlockHeight(context.Background(), "ab"); err == nil {
+		t.Fatal("expected block height to fail")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("expected 2 spans, got: %d", len(ended))
+	}
+	if ended[0].Name() != "ReceiptLookup" || ended[1].Name() != "BlockHeight" {
+		t.Fatalf("unexpected span names: %s %s", ended[0].Name(), ended[1].Name())
+	}
+	attributes := attribute.NewSet(ended[0].Attributes()...)
+	for key, want := range map[attribute.Key]attribute.Value{
+		attributeChannelID:     attribute.StringValue("ab"),
+		attributeTransactionID: attribute.StringValue("01"),
+		attributeStatusCode:    attribute.IntValue(http.StatusOK),
+	} {
+		if got, _ := attributes.Value(key); got != want {
+			t.Errorf("expected %s to be %v, got: %v", key, want.Emit(), got.Emit())
+		}
+	}
+	if size, _ := attributes.Value(attributeResponseSize); size.AsInt64() == 0 {
+		t.Error("expected a response size")
+	}
+	if ended[1].Status().Code != codes.Error {
+		t.Errorf("expected an error status, got: %v", ended[1].Status())
+	}
+
+	data := metricdata.ResourceMetrics{}
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			found[m.Name] = true
+			if m.Name == "mazzaroth.client.errors" {
+				sum := m.Data.(metricdata.Sum[int64])
+				if len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 1 {
+					t.Errorf("expected a single error, got: %v", sum.DataPoints)
+				}
+			}
+		}
+	}
+	for _, name := range []string{"mazzaroth.client.duration", "mazzaroth.client.response.size", "mazzaroth.client.errors"} {
+		if !found[name] {
+			t.Errorf("missing metric %s", name)
+		}
+	}
+}
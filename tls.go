@@ -0,0 +1,97 @@
+package mazzaroth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// tlsOptions collects the tls options of a client until it is created.
+type tlsOptions struct {
+	certificates []tls.Certificate
+	certFiles    [][2]string
+	caFiles      []string
+	serverName   string
+	minVersion   uint16
+}
+
+// set reports whether any tls option was given.
+func (o *tlsOptions) set() bool {
+	return len(o.certificates) > 0 || len(o.certFiles) > 0 || len(o.caFiles) > 0 || o.serverName != "" || o.minVersion != 0
+}
+
+// config builds the tls config of the options on top of base, which may be
+// nil. Connections require TLS 1.2 at least unless another minimum version
+// is given.
+func (o *tlsOptions) config(base *tls.Config) (*tls.Config, error) {
+	config := &tls.Config{}
+	if base != nil {
+		config = base.Clone()
+	}
+
+	config.Certificates = append(config.Certificates, o.certificates...)
+	for _, files := range o.certFiles {
+		cert, err := tls.LoadX509KeyPair(files[0], files[1])
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load client certificate")
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+
+	if len(o.caFiles) > 0 {
+		if config.RootCAs == nil {
+			config.RootCAs = x509.NewCertPool()
+		}
+		for _, file := range o.caFiles {
+			pem, err := os.ReadFile(file)
+			if err != nil {
+				return nil, errors.Wrap(err, "unable to read root ca")
+			}
+			if !config.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, errors.Errorf("no certificates found in %s", file)
+			}
+		}
+	}
+
+	if o.serverName != "" {
+		config.ServerName = o.serverName
+	}
+	if o.minVersion != 0 {
+		config.MinVersion = o.minVersion
+	} else if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	return config, nil
+}
+
+// withTLS returns a copy of client whose transport uses the tls options, or
+// client itself if none were given. The transport of client has to be an
+// *http.Transport, or nil for the default one.
+func (o *tlsOptions) withTLS(client *http.Client) (*http.Client, error) {
+	if !o.set() {
+		return client, nil
+	}
+
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, errors.New("tls options require the http client to use an *http.Transport")
+	}
+
+	config, err := o.config(transport.TLSClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = config
+
+	withTLS := *client
+	withTLS.Transport = transport
+	return &withTLS, nil
+}
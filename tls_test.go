@@ -0,0 +1,107 @@
+package mazzaroth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeClientCertificate creates a self signed client certificate, writes
+// it and its key to dir and returns the file names and a pool trusting it.
+func writeClientCertificate(t *testing.T, dir string) (string, string, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, clientCAs := writeClientCertificate(t, dir)
+
+	server := httptest.NewUnstartedServer(&xdrNode{})
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	client, err := NewMazzarothClient(WithAddress(server.URL), WithRootCAFiles(caFile),
+		WithClientCertificateFiles(certFile, keyFile), WithServerName("example.com"), WithMinTLSVersion(tls.VersionTLS13))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReceiptLookup(ctx, "ab", "01"); err != nil {
+		t.Fatal(err)
+	}
+	config := client.httpClient.Transport.(*http.Transport).TLSClientConfig
+	if config.MinVersion != tls.VersionTLS13 || config.ServerName != "example.com" {
+		t.Fatalf("unexpected tls config: %v %s", config.MinVersion, config.ServerName)
+	}
+
+	client, err = NewMazzarothClient(WithAddress(server.URL), WithRootCAFiles(caFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReceiptLookup(ctx, "ab", "01"); err == nil {
+		t.Fatal("expected the node to require a client certificate")
+	}
+
+	if _, err := NewMazzarothClient(WithRootCAFiles(filepath.Join(dir, "missing.pem"))); err == nil {
+		t.Fatal("expected a missing root ca to fail")
+	}
+	if _, err := NewMazzarothClient(WithRootCAFiles(keyFile)); err == nil {
+		t.Fatal("expected a file without certificates to fail")
+	}
+}
@@ -9,6 +9,7 @@ import (
 type TransactionBuilder struct {
 	sender  xdr.ID
 	channel xdr.ID
+	logger  Logger
 }
 
 // Transaction returns a transactionBuilder with a empty xdr.transaction
@@ -19,12 +20,19 @@ func Transaction(sender, channel xdr.ID) *TransactionBuilder {
 	}
 }
 
+// Logger sets the logger that the call and contract builders log signing
+// events to
+func (txb *TransactionBuilder) Logger(logger Logger) *TransactionBuilder {
+	txb.logger = logger
+	return txb
+}
+
 // Call
 func (txb *TransactionBuilder) Call(nonce, blockExpirationNumber uint64) *CallBuilder {
-	return new(CallBuilder).Call(&txb.sender, &txb.channel, nonce, blockExpirationNumber)
+	return new(CallBuilder).Call(&txb.sender, &txb.channel, nonce, blockExpirationNumber).Logger(txb.logger)
 }
 
 // Contract
 func (txb *TransactionBuilder) Contract(nonce, blockExpirationNumber uint64) *ContractBuilder {
-	return new(ContractBuilder).Contract(&txb.sender, &txb.channel, nonce, blockExpirationNumber)
+	return new(ContractBuilder).Contract(&txb.sender, &txb.channel, nonce, blockExpirationNumber).Logger(txb.logger)
 }
@@ -0,0 +1,31 @@
+package verify
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrHeightGap triggered if a header does not follow the height of the one before it
+	ErrHeightGap = errors.New("block height is not continuous")
+	// ErrParentMismatch triggered if a header does not link to the hash of the one before it
+	ErrParentMismatch = errors.New("previous header hash does not match")
+	// ErrHashMismatch triggered if the recomputed hash of an entity does not match the expected hash
+	ErrHashMismatch = errors.New("hash does not match")
+)
+
+// InconsistencyError reports the first height at which data served by a
+// node stopped being consistent.
+type InconsistencyError struct {
+	Height uint64
+	Err    error
+}
+
+func (e *InconsistencyError) Error() string {
+	return fmt.Sprintf("inconsistent block at height %d: %v", e.Height, e.Err)
+}
+
+func (e *InconsistencyError) Unwrap() error {
+	return e.Err
+}
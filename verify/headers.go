@@ -0,0 +1,72 @@
+package verify
+
+import (
+	"github.com/kochavalabs/crypto"
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+// hasher is the hash function used by mazzaroth nodes.
+var hasher = &crypto.Sha3_256Hasher{}
+
+// HeaderHash returns the hash of the xdr encoding of a block header, which
+// is the hash the following header links to.
+func HeaderHash(header *xdr.BlockHeader) (xdr.Hash, error) {
+	b, err := header.MarshalBinary()
+	if err != nil {
+		return xdr.Hash{}, errors.Wrap(err, "in header.MarshalBinary")
+	}
+	return xdr.HashFromSlice(hasher.Hash(b))
+}
+
+// Header checks that the recomputed hash of header matches expected, such as
+// the id a header was looked up with.
+func Header(header *xdr.BlockHeader, expected xdr.Hash) error {
+	hash, err := HeaderHash(header)
+	if err != nil {
+		return err
+	}
+	if hash != expected {
+		return &InconsistencyError{Height: header.BlockHeight, Err: ErrHashMismatch}
+	}
+	return nil
+}
+
+// HeaderChain checks that headers, as returned by BlockHeaderList, form a
+// chain: heights increase one by one and every header links to the hash of
+// the header before it. The first inconsistency is reported as an
+// *InconsistencyError.
+func HeaderChain(headers []xdr.BlockHeader) error {
+	if len(headers) == 0 {
+		return nil
+	}
+	return HeaderChainFrom(&headers[0], headers[1:])
+}
+
+// HeaderChainFrom checks that headers form a chain extending the already
+// trusted header.
+func HeaderChainFrom(trusted *xdr.BlockHeader, headers []xdr.BlockHeader) error {
+	previous := trusted
+	previousHash, err := HeaderHash(previous)
+	if err != nil {
+		return err
+	}
+
+	for i := range headers {
+		header := &headers[i]
+		if header.BlockHeight != previous.BlockHeight+1 {
+			return &InconsistencyError{Height: header.BlockHeight, Err: ErrHeightGap}
+		}
+		if header.PreviousHeader != previousHash {
+			return &InconsistencyError{Height: header.BlockHeight, Err: ErrParentMismatch}
+		}
+
+		hash, err := HeaderHash(header)
+		if err != nil {
+			return err
+		}
+		previous, previousHash = header, hash
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package verify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+func testChain(t *testing.T, n int) []xdr.BlockHeader {
+	headers := make([]xdr.BlockHeader, n)
+	for i := range headers {
+		headers[i] = xdr.BlockHeader{
+			BlockHeight:       uint64(i + 10),
+			TransactionHeight: uint64(i * 3),
+			Status:            xdr.StatusFINALIZED,
+		}
+		if i > 0 {
+			hash, err := HeaderHash(&headers[i-1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			headers[i].PreviousHeader = hash
+		}
+	}
+	return headers
+}
+
+func TestHeaderChain(t *testing.T) {
+	headers := testChain(t, 5)
+	if err := HeaderChain(headers); err != nil {
+		t.Fatal(err)
+	}
+	if err := HeaderChain(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHeaderChainTampered(t *testing.T) {
+	headers := testChain(t, 5)
+	headers[2].StateRoot[0] = 1
+
+	err := HeaderChain(headers)
+	var inconsistent *InconsistencyError
+	if !errors.As(err, &inconsistent) {
+		t.Fatalf("expected an InconsistencyError, got: %v", err)
+	}
+	if inconsistent.Height != 13 || !errors.Is(err, ErrParentMismatch) {
+		t.Fatalf("expected parent mismatch at height 13, got: %v", err)
+	}
+}
+
+func TestHeaderChainGap(t *testing.T) {
+	headers := testChain(t, 5)
+	headers = append(headers[:2], headers[3:]...)
+
+	err := HeaderChain(headers)
+	if !errors.Is(err, ErrHeightGap) {
+		t.Fatalf("expected height gap, got: %v", err)
+	}
+}
+
+func TestHeaderChainFrom(t *testing.T) {
+	headers := testChain(t, 5)
+	if err := HeaderChainFrom(&headers[0], headers[1:]); err != nil {
+		t.Fatal(err)
+	}
+
+	forked := headers[0]
+	forked.StateRoot[0] = 1
+	if err := HeaderChainFrom(&forked, headers[1:]); !errors.Is(err, ErrParentMismatch) {
+		t.Fatalf("expected parent mismatch, got: %v", err)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	header := testChain(t, 1)[0]
+	hash, err := HeaderHash(&header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Header(&header, hash); err != nil {
+		t.Fatal(err)
+	}
+	header.BlockHeight++
+	if err := Header(&header, hash); !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("expected hash mismatch, got: %v", err)
+	}
+}
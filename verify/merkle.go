@@ -0,0 +1,202 @@
+package verify
+
+import (
+	"encoding"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+// The transaction and receipt roots of a block header are taken to be the
+// roots of merkle trees over the transactions of the block, in block order,
+// and over their receipts. Leaves are the hashes of the xdr encoding of each
+// entry, inner nodes the hash of the concatenation of their children. A
+// node left without a sibling on an odd sized level is carried up
+// unchanged, and the root of an empty tree is the zero hash. Leaves and
+// inner nodes are hashed alike, without domain separation, so a proof only
+// binds an entry whose leaf hash the verifier computed itself, as
+// VerifyTransactionProof and VerifyReceiptProof do.
+//
+// This construction has not been checked against a node implementation or
+// test vectors from a node yet. TestConformanceMerkleRoots of the mazzaroth
+// package checks it against the latest blocks of the node set by
+// MAZZAROTH_TEST_ADDRESS and MAZZAROTH_TEST_CHANNEL.
+
+// ErrIndexOutOfRange triggered if a proof is requested for an entry that does not exist
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// ProofNode is a sibling hash on the path from a leaf to the merkle root.
+type ProofNode struct {
+	Hash xdr.Hash `json:"hash"`
+	// Left is set if the sibling is the left child of their parent.
+	Left bool `json:"left"`
+}
+
+// Proof is a merkle inclusion proof for a single transaction or receipt.
+type Proof struct {
+	Index int         `json:"index"`
+	Path  []ProofNode `json:"path"`
+}
+
+// TransactionsRoot computes the transactions merkle root of txs.
+func TransactionsRoot(txs []xdr.Transaction) (xdr.Hash, error) {
+	leaves, err := transactionLeaves(txs)
+	if err != nil {
+		return xdr.Hash{}, err
+	}
+	return merkleRoot(leaves), nil
+}
+
+// ReceiptsRoot computes the receipts merkle root of receipts.
+func ReceiptsRoot(receipts []xdr.Receipt) (xdr.Hash, error) {
+	leaves, err := receiptLeaves(receipts)
+	if err != nil {
+		return xdr.Hash{}, err
+	}
+	return merkleRoot(leaves), nil
+}
+
+// Block checks that the transactions of block match the transactions merkle
+// root its header commits to.
+func Block(block *xdr.Block) error {
+	root, err := TransactionsRoot(block.Transactions)
+	if err != nil {
+		return err
+	}
+	if root != block.Header.TransactionsMerkleRoot {
+		return &InconsistencyError{Height: block.Header.BlockHeight, Err: ErrHashMismatch}
+	}
+	return nil
+}
+
+// TransactionProof builds the inclusion proof of the transaction at index in
+// block.
+func TransactionProof(block *xdr.Block, index int) (*Proof, error) {
+	if index < 0 || index >= len(block.Transactions) {
+		return nil, ErrIndexOutOfRange
+	}
+	leaves, err := transactionLeaves(block.Transactions)
+	if err != nil {
+		return nil, err
+	}
+	return &Proof{Index: index, Path: merklePath(leaves, index)}, nil
+}
+
+// ReceiptProof builds the inclusion proof of the receipt at index in
+// receipts, the receipts of all transactions of a block in block order.
+func ReceiptProof(receipts []xdr.Receipt, index int) (*Proof, error) {
+	if index < 0 || index >= len(receipts) {
+		return nil, ErrIndexOutOfRange
+	}
+	leaves, err := receiptLeaves(receipts)
+	if err != nil {
+		return nil, err
+	}
+	return &Proof{Index: index, Path: merklePath(leaves, index)}, nil
+}
+
+// VerifyTransactionProof checks that proof proves tx to be included in the
+// block of header.
+func VerifyTransactionProof(header *xdr.BlockHeader, tx *xdr.Transaction, proof *Proof) error {
+	return verifyProof(header, header.TransactionsMerkleRoot, tx, proof)
+}
+
+// VerifyReceiptProof checks that proof proves receipt to be included in the
+// block of header.
+func VerifyReceiptProof(header *xdr.BlockHeader, receipt *xdr.Receipt, proof *Proof) error {
+	return verifyProof(header, header.TransactionsReceiptRoot, receipt, proof)
+}
+
+func verifyProof(header *xdr.BlockHeader, root xdr.Hash, entry encoding.BinaryMarshaler, proof *Proof) error {
+	hash, err := leafHash(entry)
+	if err != nil {
+		return err
+	}
+	for _, node := range proof.Path {
+		if node.Left {
+			hash = nodeHash(node.Hash, hash)
+		} else {
+			hash = nodeHash(hash, node.Hash)
+		}
+	}
+	if hash != root {
+		return &InconsistencyError{Height: header.BlockHeight, Err: ErrHashMismatch}
+	}
+	return nil
+}
+
+func transactionLeaves(txs []xdr.Transaction) ([]xdr.Hash, error) {
+	leaves := make([]xdr.Hash, len(txs))
+	for i := range txs {
+		hash, err := leafHash(&txs[i])
+		if err != nil {
+			return nil, err
+		}
+		leaves[i] = hash
+	}
+	return leaves, nil
+}
+
+func receiptLeaves(receipts []xdr.Receipt) ([]xdr.Hash, error) {
+	leaves := make([]xdr.Hash, len(receipts))
+	for i := range receipts {
+		hash, err := leafHash(&receipts[i])
+		if err != nil {
+			return nil, err
+		}
+		leaves[i] = hash
+	}
+	return leaves, nil
+}
+
+func leafHash(entry encoding.BinaryMarshaler) (xdr.Hash, error) {
+	b, err := entry.MarshalBinary()
+	if err != nil {
+		return xdr.Hash{}, errors.Wrap(err, "in MarshalBinary")
+	}
+	return xdr.HashFromSlice(hasher.Hash(b))
+}
+
+func nodeHash(left, right xdr.Hash) xdr.Hash {
+	var hash xdr.Hash
+	copy(hash[:], hasher.Hash(left[:], right[:]))
+	return hash
+}
+
+// nextLevel hashes the nodes of a tree level pairwise into the level above.
+func nextLevel(level []xdr.Hash) []xdr.Hash {
+	next := make([]xdr.Hash, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, nodeHash(level[i], level[i+1]))
+	}
+	return next
+}
+
+func merkleRoot(leaves []xdr.Hash) xdr.Hash {
+	if len(leaves) == 0 {
+		return xdr.Hash{}
+	}
+	level := leaves
+	for len(level) > 1 {
+		level = nextLevel(level)
+	}
+	return level[0]
+}
+
+func merklePath(leaves []xdr.Hash, index int) []ProofNode {
+	var path []ProofNode
+	level := leaves
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			path = append(path, ProofNode{Hash: level[sibling], Left: sibling < index})
+		}
+		level = nextLevel(level)
+		index /= 2
+	}
+	return path
+}
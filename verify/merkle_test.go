@@ -0,0 +1,98 @@
+package verify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+func testBlock(t *testing.T, n int) (*xdr.Block, []xdr.Receipt) {
+	block := &xdr.Block{
+		Header:       xdr.BlockHeader{BlockHeight: 7},
+		Transactions: make([]xdr.Transaction, n),
+	}
+	receipts := make([]xdr.Receipt, n)
+	for i := range block.Transactions {
+		block.Transactions[i] = xdr.Transaction{
+			Data: xdr.Data{
+				Nonce: uint64(i),
+				Category: xdr.Category{
+					Type: xdr.CategoryTypeCALL,
+					Call: &xdr.Call{Function: "test"},
+				},
+			},
+		}
+		receipts[i] = xdr.Receipt{Status: xdr.StatusSUCCESS, Result: "ok"}
+		receipts[i].TransactionID[0] = byte(i)
+	}
+
+	var err error
+	block.Header.TransactionsMerkleRoot, err = TransactionsRoot(block.Transactions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.Header.TransactionsReceiptRoot, err = ReceiptsRoot(receipts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return block, receipts
+}
+
+func TestTransactionProof(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8} {
+		block, _ := testBlock(t, n)
+		if err := Block(block); err != nil {
+			t.Fatal(err)
+		}
+		for i := range block.Transactions {
+			proof, err := TransactionProof(block, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyTransactionProof(&block.Header, &block.Transactions[i], proof); err != nil {
+				t.Fatalf("size %d index %d: %v", n, i, err)
+			}
+		}
+	}
+}
+
+func TestTransactionProofTampered(t *testing.T) {
+	block, _ := testBlock(t, 5)
+	proof, err := TransactionProof(block, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := block.Transactions[3]
+	tx.Data.Nonce = 100
+	if err := VerifyTransactionProof(&block.Header, &tx, proof); !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("expected hash mismatch, got: %v", err)
+	}
+
+	block.Transactions[3] = tx
+	if err := Block(block); !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("expected hash mismatch, got: %v", err)
+	}
+}
+
+func TestReceiptProof(t *testing.T) {
+	block, receipts := testBlock(t, 6)
+	for i := range receipts {
+		proof, err := ReceiptProof(receipts, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyReceiptProof(&block.Header, &receipts[i], proof); err != nil {
+			t.Fatalf("index %d: %v", i, err)
+		}
+		// a receipt proof does not prove the transaction at the same index
+		if err := VerifyTransactionProof(&block.Header, &block.Transactions[i], proof); err == nil {
+			t.Fatalf("index %d: expected transaction to fail the receipt proof", i)
+		}
+	}
+
+	if _, err := ReceiptProof(receipts, len(receipts)); err != ErrIndexOutOfRange {
+		t.Fatalf("expected index out of range, got: %v", err)
+	}
+}
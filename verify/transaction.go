@@ -0,0 +1,51 @@
+package verify
+
+import (
+	"crypto/ed25519"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidSignature triggered if a transaction is not signed by its sender
+var ErrInvalidSignature = errors.New("invalid transaction signature")
+
+// TransactionID returns the id of a transaction, the hash of its xdr
+// encoding. It has not been checked against ids returned by a node yet,
+// TestConformanceTransactionID of the mazzaroth package checks it against
+// the node set by MAZZAROTH_TEST_ADDRESS.
+func TransactionID(tx *xdr.Transaction) (xdr.ID, error) {
+	b, err := tx.MarshalBinary()
+	if err != nil {
+		return xdr.ID{}, errors.Wrap(err, "in tx.MarshalBinary")
+	}
+	return xdr.IDFromSlice(hasher.Hash(b))
+}
+
+// Signature checks that tx was signed by its sender.
+func Signature(tx *xdr.Transaction) error {
+	b, err := tx.Data.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "in data.MarshalBinary")
+	}
+	if !ed25519.Verify(tx.Sender[:], b, tx.Signature[:]) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Transaction checks that tx is signed by its sender and has the id it was
+// looked up with.
+func Transaction(tx *xdr.Transaction, id xdr.ID) error {
+	if err := Signature(tx); err != nil {
+		return err
+	}
+	txID, err := TransactionID(tx)
+	if err != nil {
+		return err
+	}
+	if txID != id {
+		return ErrHashMismatch
+	}
+	return nil
+}
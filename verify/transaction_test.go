@@ -0,0 +1,50 @@
+package verify
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+func TestTransaction(t *testing.T) {
+	privateKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	sender, err := xdr.IDFromPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &xdr.Transaction{
+		Sender: sender,
+		Data: xdr.Data{
+			Nonce: 1,
+			Category: xdr.Category{
+				Type: xdr.CategoryTypeCALL,
+				Call: &xdr.Call{Function: "test"},
+			},
+		},
+	}
+	dataBytes, err := tx.Data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Signature, err = xdr.SignatureFromSlice(ed25519.Sign(privateKey, dataBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := TransactionID(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Transaction(tx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := Transaction(tx, xdr.ID{}); err != ErrHashMismatch {
+		t.Fatalf("expected hash mismatch, got: %v", err)
+	}
+
+	tx.Data.Nonce++
+	if err := Transaction(tx, id); err != ErrInvalidSignature {
+		t.Fatalf("expected invalid signature, got: %v", err)
+	}
+}
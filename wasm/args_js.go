@@ -0,0 +1,142 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"syscall/js"
+
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+// maxSafeInteger is the largest integer a JavaScript number holds exactly.
+const maxSafeInteger = 1<<53 - 1
+
+// jsArgs validates and converts the arguments passed to a function from
+// JavaScript. The first failure is kept in err and turns every following
+// conversion into a no-op, so callers only have to check err once.
+type jsArgs struct {
+	values []js.Value
+	names  []string
+	err    error
+}
+
+// parseArgs expects values to hold at least one argument for each of names.
+func parseArgs(values []js.Value, names ...string) *jsArgs {
+	a := &jsArgs{values: values, names: names}
+	if len(values) < len(names) {
+		a.err = invalidArgument("expected %d arguments %v, got %d", len(names), names, len(values))
+	}
+	return a
+}
+
+// check verifies that argument i is of type t.
+func (a *jsArgs) check(i int, t js.Type) bool {
+	if a.err != nil {
+		return false
+	}
+	if a.values[i].Type() != t {
+		a.err = invalidArgument("%s must be a %s", a.names[i], t)
+		return false
+	}
+	return true
+}
+
+// string returns argument i, which must be a string.
+func (a *jsArgs) string(i int) string {
+	if !a.check(i, js.TypeString) {
+		return ""
+	}
+	return a.values[i].String()
+}
+
+// bool returns argument i, which must be a boolean.
+func (a *jsArgs) bool(i int) bool {
+	if !a.check(i, js.TypeBoolean) {
+		return false
+	}
+	return a.values[i].Bool()
+}
+
+// int returns argument i, which must be a non negative integer number.
+func (a *jsArgs) int(i int) int {
+	if !a.check(i, js.TypeNumber) {
+		return 0
+	}
+	f := a.values[i].Float()
+	if f != math.Trunc(f) || f < 0 || f > maxSafeInteger {
+		a.err = invalidArgument("%s must be a non negative integer", a.names[i])
+		return 0
+	}
+	return int(f)
+}
+
+// uint64 returns argument i, which must be either a non negative integer
+// number or a decimal string. Strings allow values beyond maxSafeInteger.
+func (a *jsArgs) uint64(i int) uint64 {
+	if a.err != nil {
+		return 0
+	}
+	switch a.values[i].Type() {
+	case js.TypeNumber:
+		return uint64(a.int(i))
+	case js.TypeString:
+		u, err := strconv.ParseUint(a.values[i].String(), 10, 64)
+		if err != nil {
+			a.err = invalidArgument("%s must be an unsigned 64 bit integer", a.names[i])
+		}
+		return u
+	}
+	a.err = invalidArgument("%s must be a number or a decimal string", a.names[i])
+	return 0
+}
+
+// id returns argument i, which must be a hex encoded 32 byte id.
+func (a *jsArgs) id(i int) xdr.ID {
+	s := a.string(i)
+	if a.err != nil {
+		return xdr.ID{}
+	}
+	id, err := xdr.IDFromHexString(s)
+	if err != nil {
+		a.err = invalidArgument("%s must be a hex encoded 32 byte id", a.names[i])
+	}
+	return id
+}
+
+// json returns argument i as json. Objects are serialized with
+// JSON.stringify while strings are expected to already hold json.
+func (a *jsArgs) json(i int) []byte {
+	if a.err != nil {
+		return nil
+	}
+	switch a.values[i].Type() {
+	case js.TypeString:
+		return []byte(a.values[i].String())
+	case js.TypeObject:
+		return []byte(js.Global().Get("JSON").Call("stringify", a.values[i]).String())
+	}
+	a.err = invalidArgument("%s must be an object or a json string", a.names[i])
+	return nil
+}
+
+// bytes returns a copy of argument i, which must be a Uint8Array, another
+// typed array view or an ArrayBuffer.
+func (a *jsArgs) bytes(i int) []byte {
+	if a.err != nil {
+		return nil
+	}
+	value := a.values[i]
+	switch {
+	case value.InstanceOf(js.Global().Get("Uint8Array")):
+	case value.InstanceOf(js.Global().Get("ArrayBuffer")):
+		value = js.Global().Get("Uint8Array").New(value)
+	case js.Global().Get("ArrayBuffer").Call("isView", value).Bool():
+		value = js.Global().Get("Uint8Array").New(value.Get("buffer"), value.Get("byteOffset"), value.Get("byteLength"))
+	default:
+		a.err = invalidArgument("%s must be a Uint8Array or an ArrayBuffer", a.names[i])
+		return nil
+	}
+	b := make([]byte, value.Length())
+	js.CopyBytesToGo(b, value)
+	return b
+}
@@ -7,29 +7,38 @@ import (
 	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
 )
 
-func callFunction(callBuilder *mazzaroth.CallBuilder) js.Func {
-	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		callBuilder.Function(args[0].String())
-		return map[string]interface{}{
-			"Arguments": callArguments(callBuilder),
+func callFunction(callBuilder mazzaroth.CallBuilder) js.Value {
+	return function(func(args []js.Value) (interface{}, error) {
+		a := parseArgs(args, "name")
+		name := a.string(0)
+		if a.err != nil {
+			return nil, a.err
 		}
+		next := callBuilder
+		next.Function(name)
+		return map[string]interface{}{
+			"Arguments": callArguments(next),
+		}, nil
 	})
 }
 
-func callArguments(callBuilder *mazzaroth.CallBuilder) js.Func {
+func callArguments(callBuilder mazzaroth.CallBuilder) js.Value {
 	// dev note:: we are expecting args to be a string and that the caller
 	// will pass multiple string args i.e func("x","y","z")
 	// Alternatively we could construct a json array and pass a
 	// json string to this function and unmarshal it into a []xdr.Agruments
-	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		arguments := make([]xdr.Argument, 0, 0)
-		for _, arg := range args {
-			a := xdr.Argument(arg.String())
-			arguments = append(arguments, a)
+	return function(func(args []js.Value) (interface{}, error) {
+		arguments := make([]xdr.Argument, 0, len(args))
+		for i, arg := range args {
+			if arg.Type() != js.TypeString {
+				return nil, invalidArgument("argument %d must be a string", i)
+			}
+			arguments = append(arguments, xdr.Argument(arg.String()))
 		}
-		callBuilder.Arguments(arguments...)
+		next := callBuilder
+		next.Arguments(arguments...)
 		return map[string]interface{}{
-			"Sign": sign(callBuilder),
-		}
+			"Sign": sign(&next),
+		}, nil
 	})
 }
@@ -10,163 +10,140 @@ import (
 	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
 )
 
-const timeout = 10 * time.Second
+const defaultTimeout = 10 * time.Second
 
 type mazzarothJsWrapperClient struct {
-	client mazzaroth.Client
+	client  mazzaroth.Client
+	timeout time.Duration
+}
+
+// submitResult is the object a TransactionSubmit promise resolves with.
+type submitResult struct {
+	ID      *xdr.ID      `json:"id"`
+	Receipt *xdr.Receipt `json:"receipt,omitempty"`
 }
 
 func (m *mazzarothJsWrapperClient) blockHeaderLookup() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-		blockHeader, err := m.client.BlockHeaderLookup(ctx, args[0].String(), args[1].String())
-		if err != nil {
-			return err.Error()
-		}
-		blockHeaderJson, err := json.Marshal(blockHeader)
-		if err != nil {
-			return err.Error()
-		}
-		return js.ValueOf(string(blockHeaderJson))
+		a := parseArgs(args, "channelID", "blockID")
+		channelID, blockID := a.string(0), a.string(1)
+		if a.err != nil {
+			return rejected(a.err)
+		}
+		return promise(m.timeout, func(ctx context.Context) (interface{}, error) {
+			return m.client.BlockHeaderLookup(ctx, channelID, blockID)
+		})
 	})
 }
 
 func (m *mazzarothJsWrapperClient) blockHeaderList() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-		blockHeaderList, err := m.client.BlockHeaderList(ctx, args[0].String(), args[1].Int(), args[2].Int())
-		if err != nil {
-			return err.Error()
-		}
-		blockHeaderListJson, err := json.Marshal(blockHeaderList)
-		if err != nil {
-			return err.Error()
-		}
-		return js.ValueOf(string(blockHeaderListJson))
+		a := parseArgs(args, "channelID", "blockHeight", "number")
+		channelID, blockHeight, number := a.string(0), a.int(1), a.int(2)
+		if a.err != nil {
+			return rejected(a.err)
+		}
+		return promise(m.timeout, func(ctx context.Context) (interface{}, error) {
+			return m.client.BlockHeaderList(ctx, channelID, blockHeight, number)
+		})
 	})
 }
 
 func (m *mazzarothJsWrapperClient) blockHeight() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-		blockHeight, err := m.client.BlockHeight(ctx, args[0].String())
-		if err != nil {
-			return err.Error()
-		}
-		blockHeightJson, err := json.Marshal(blockHeight)
-		if err != nil {
-			return err.Error()
-		}
-		return js.ValueOf(string(blockHeightJson))
+		a := parseArgs(args, "channelID")
+		channelID := a.string(0)
+		if a.err != nil {
+			return rejected(a.err)
+		}
+		return promise(m.timeout, func(ctx context.Context) (interface{}, error) {
+			return m.client.BlockHeight(ctx, channelID)
+		})
 	})
 }
 
 func (m *mazzarothJsWrapperClient) blockLookup() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-		block, err := m.client.BlockLookup(ctx, args[0].String(), args[1].String())
-		if err != nil {
-			return err.Error()
-		}
-		blockJson, err := json.Marshal(block)
-		if err != nil {
-			return err.Error()
-		}
-		return js.ValueOf(string(blockJson))
+		a := parseArgs(args, "channelID", "blockID")
+		channelID, blockID := a.string(0), a.string(1)
+		if a.err != nil {
+			return rejected(a.err)
+		}
+		return promise(m.timeout, func(ctx context.Context) (interface{}, error) {
+			return m.client.BlockLookup(ctx, channelID, blockID)
+		})
 	})
 }
 
 func (m *mazzarothJsWrapperClient) blockList() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-		blockList, err := m.client.BlockList(ctx, args[0].String(), args[1].Int(), args[2].Int())
-		if err != nil {
-			return err.Error()
-		}
-		blockListJson, err := json.Marshal(blockList)
-		if err != nil {
-			return err.Error()
-		}
-		return js.ValueOf(string(blockListJson))
+		a := parseArgs(args, "channelID", "blockHeight", "number")
+		channelID, blockHeight, number := a.string(0), a.int(1), a.int(2)
+		if a.err != nil {
+			return rejected(a.err)
+		}
+		return promise(m.timeout, func(ctx context.Context) (interface{}, error) {
+			return m.client.BlockList(ctx, channelID, blockHeight, number)
+		})
 	})
 }
 
 func (m *mazzarothJsWrapperClient) channelAbi() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-		channelAbi, err := m.client.ChannelAbi(ctx, args[0].String())
-		if err != nil {
-			return err.Error()
-		}
-		channelAbiJson, err := json.Marshal(channelAbi)
-		if err != nil {
-			return err.Error()
-		}
-		return js.ValueOf(string(channelAbiJson))
+		a := parseArgs(args, "channelID")
+		channelID := a.string(0)
+		if a.err != nil {
+			return rejected(a.err)
+		}
+		return promise(m.timeout, func(ctx context.Context) (interface{}, error) {
+			return m.client.ChannelAbi(ctx, channelID)
+		})
 	})
 }
 
 func (m *mazzarothJsWrapperClient) receiptLookup() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-		receipt, err := m.client.ReceiptLookup(ctx, args[0].String(), args[1].String())
-		if err != nil {
-			return err.Error()
-		}
-		receiptJson, err := json.Marshal(receipt)
-		if err != nil {
-			return err.Error()
-		}
-		return js.ValueOf(string(receiptJson))
+		a := parseArgs(args, "channelID", "transactionID")
+		channelID, transactionID := a.string(0), a.string(1)
+		if a.err != nil {
+			return rejected(a.err)
+		}
+		return promise(m.timeout, func(ctx context.Context) (interface{}, error) {
+			return m.client.ReceiptLookup(ctx, channelID, transactionID)
+		})
 	})
 }
 
 func (m *mazzarothJsWrapperClient) transactionLookup() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-		tx, err := m.client.TransactionLookup(ctx, args[0].String(), args[1].String())
-		if err != nil {
-			return err.Error()
-		}
-		txJson, err := json.Marshal(tx)
-		if err != nil {
-			return err.Error()
-		}
-		return js.ValueOf(string(txJson))
+		a := parseArgs(args, "channelID", "transactionID")
+		channelID, transactionID := a.string(0), a.string(1)
+		if a.err != nil {
+			return rejected(a.err)
+		}
+		return promise(m.timeout, func(ctx context.Context) (interface{}, error) {
+			return m.client.TransactionLookup(ctx, channelID, transactionID)
+		})
 	})
 }
 
 func (m *mazzarothJsWrapperClient) transactionSubmit() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-		tx := &xdr.Transaction{}
-		if err := json.Unmarshal([]byte(args[0].String()), tx); err != nil {
-			return err.Error()
+		a := parseArgs(args, "transaction")
+		txJson := a.json(0)
+		if a.err != nil {
+			return rejected(a.err)
 		}
-
-		id, receipt, err := m.client.TransactionSubmit(ctx, tx)
-		if err != nil {
-			return err.Error()
+		tx := &xdr.Transaction{}
+		if err := json.Unmarshal(txJson, tx); err != nil {
+			return rejected(invalidArgument("transaction is not a valid transaction: %v", err))
 		}
-		if receipt != nil {
-			receiptJson, err := json.Marshal(receipt)
+		return promise(m.timeout, func(ctx context.Context) (interface{}, error) {
+			id, receipt, err := m.client.TransactionSubmit(ctx, tx)
 			if err != nil {
-				return err.Error()
+				return nil, err
 			}
-			return map[string]interface{}{
-				"id":      &id,
-				"receipt": receiptJson,
-			}
-		}
-		return &id
+			return &submitResult{ID: id, Receipt: receipt}, nil
+		})
 	})
 }
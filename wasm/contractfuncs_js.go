@@ -2,48 +2,52 @@ package main
 
 import (
 	"encoding/json"
-	"errors"
 	"syscall/js"
 
 	"github.com/kochavalabs/mazzaroth-go"
 	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
 )
 
-func delete(contractBuilder *mazzaroth.ContractBuilder) js.Func {
-	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+func delete(contractBuilder mazzaroth.ContractBuilder) js.Value {
+	return function(func(args []js.Value) (interface{}, error) {
+		next := contractBuilder
+		next.Delete()
 		return map[string]interface{}{
-			"Sign": sign(contractBuilder),
-		}
+			"Sign": sign(&next),
+		}, nil
 	})
 }
 
-func deploy(contractBuilder *mazzaroth.ContractBuilder) js.Func {
-	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		if len(args) < 3 {
-			return errors.New("missing arguments")
-		}
-		owner, err := xdr.IDFromHexString(args[0].String())
-		if err != nil {
-			return err.Error()
+func deploy(contractBuilder mazzaroth.ContractBuilder) js.Value {
+	return function(func(args []js.Value) (interface{}, error) {
+		a := parseArgs(args, "owner", "version", "abi", "contract")
+		owner, version, abiJson, contract := a.id(0), a.string(1), a.json(2), a.bytes(3)
+		if a.err != nil {
+			return nil, a.err
 		}
-		version := args[1].String()
 		abi := &xdr.Abi{}
-		if err := json.Unmarshal([]byte(args[2].String()), abi); err != nil {
-			return err.Error()
+		if err := json.Unmarshal(abiJson, abi); err != nil {
+			return nil, invalidArgument("abi is not a valid abi: %v", err)
 		}
-		contractBytes := []byte(args[3].String())
-		contractBuilder.Deploy(owner, version, abi, contractBytes)
+		next := contractBuilder
+		next.Deploy(owner, version, abi, contract)
 		return map[string]interface{}{
-			"Sign": sign(contractBuilder),
-		}
+			"Sign": sign(&next),
+		}, nil
 	})
 }
 
-func pause(contractBuilder *mazzaroth.ContractBuilder) js.Func {
-	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		contractBuilder.Pause(args[0].Bool())
-		return map[string]interface{}{
-			"Sign": sign(contractBuilder),
+func pause(contractBuilder mazzaroth.ContractBuilder) js.Value {
+	return function(func(args []js.Value) (interface{}, error) {
+		a := parseArgs(args, "pause")
+		paused := a.bool(0)
+		if a.err != nil {
+			return nil, a.err
 		}
+		next := contractBuilder
+		next.Pause(paused)
+		return map[string]interface{}{
+			"Sign": sign(&next),
+		}, nil
 	})
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"syscall/js"
+)
+
+// Error codes set on the code property of the Error objects handed to
+// JavaScript, so callers can tell failures apart without parsing messages.
+const (
+	codeInvalidArgument = "INVALID_ARGUMENT"
+	codeRequestFailed   = "REQUEST_FAILED"
+	codeEncodingFailed  = "ENCODING_FAILED"
+	codeSigningFailed   = "SIGNING_FAILED"
+)
+
+// codedError is an error that carries the code reported to JavaScript.
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+// withCode attaches code to err.
+func withCode(code string, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// invalidArgument returns an error reporting a bad argument from JavaScript.
+func invalidArgument(format string, a ...interface{}) error {
+	return withCode(codeInvalidArgument, fmt.Errorf(format, a...))
+}
+
+// newJsError converts err into a JavaScript Error object with code and
+// message properties. Errors that do not carry a code are given defaultCode.
+func newJsError(err error, defaultCode string) js.Value {
+	code := defaultCode
+	var coded *codedError
+	if errors.As(err, &coded) {
+		code = coded.code
+	}
+	jsErr := js.Global().Get("Error").New(err.Error())
+	jsErr.Set("code", code)
+	return jsErr
+}
+
+// throwIfErrorGlobal is the global mazzaroth.js hands throwIfError over in
+// before running the program.
+const throwIfErrorGlobal = "__mazzarothThrowIfError"
+
+// throwIfError wraps a function so that an Error object returned from it is
+// thrown instead. syscall/js has no way of throwing from go directly, and
+// defining the wrapper from go needs eval, which content security policies
+// commonly forbid, so it is defined by mazzaroth.js.
+var throwIfError js.Value
+
+// loadThrowIfError takes over the wrapper defined by mazzaroth.js, if the
+// program was loaded through it.
+func loadThrowIfError() {
+	throwIfError = js.Global().Get(throwIfErrorGlobal)
+	js.Global().Delete(throwIfErrorGlobal)
+}
+
+// function creates a synchronous JavaScript function from fn, throwing an
+// Error object when fn fails. Without the wrapper of mazzaroth.js the Error
+// object is returned instead.
+func function(fn func(args []js.Value) (interface{}, error)) js.Value {
+	f := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		result, err := fn(args)
+		if err != nil {
+			return newJsError(err, codeInvalidArgument)
+		}
+		return result
+	})
+	if throwIfError.Type() != js.TypeFunction {
+		return f.Value
+	}
+	return throwIfError.Invoke(f)
+}
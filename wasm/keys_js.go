@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"syscall/js"
+
+	"github.com/kochavalabs/crypto"
+	"github.com/kochavalabs/mazzaroth-go"
+	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
+)
+
+// generateKeyPair returns a new ed25519 key pair as {privateKey, publicKey},
+// where privateKey is the hex encoded seed accepted by Sign and publicKey is
+// the hex encoded xdr.ID of the account.
+func generateKeyPair(args []js.Value) (interface{}, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"privateKey": hex.EncodeToString(privateKey.Seed()),
+		"publicKey":  hex.EncodeToString(publicKey),
+	}, nil
+}
+
+// publicKeyFromSeed returns the hex encoded xdr.ID derived from a hex
+// encoded private key seed.
+func publicKeyFromSeed(args []js.Value) (interface{}, error) {
+	a := parseArgs(args, "privateKey")
+	seedHex := a.string(0)
+	if a.err != nil {
+		return nil, a.err
+	}
+	seed, err := crypto.FromHex(seedHex)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, invalidArgument("privateKey must be a hex encoded %d byte seed", ed25519.SeedSize)
+	}
+	id, err := xdr.IDFromPublicKey(ed25519.NewKeyFromSeed(seed).Public())
+	if err != nil {
+		return nil, err
+	}
+	return hex.EncodeToString(id[:]), nil
+}
+
+// verifySignature reports whether signature is a valid signature of message
+// by publicKey, all of them hex encoded.
+func verifySignature(args []js.Value) (interface{}, error) {
+	a := parseArgs(args, "publicKey", "message", "signature")
+	publicKey, messageHex, signature := a.id(0), a.string(1), a.string(2)
+	if a.err != nil {
+		return nil, a.err
+	}
+	message, err := crypto.FromHex(messageHex)
+	if err != nil {
+		return nil, invalidArgument("message must be hex encoded")
+	}
+	sig, err := xdr.SignatureFromHexString(signature)
+	if err != nil {
+		return nil, invalidArgument("signature must be a hex encoded %d byte signature", ed25519.SignatureSize)
+	}
+	return ed25519.Verify(publicKey[:], message, sig[:]), nil
+}
+
+// verifyTransaction reports whether the signature of a transaction was made
+// by its sender over the xdr encoding of its data.
+func verifyTransaction(args []js.Value) (interface{}, error) {
+	a := parseArgs(args, "transaction")
+	txJson := a.json(0)
+	if a.err != nil {
+		return nil, a.err
+	}
+	tx := &xdr.Transaction{}
+	if err := json.Unmarshal(txJson, tx); err != nil {
+		return nil, invalidArgument("transaction is not a valid transaction: %v", err)
+	}
+	data, err := tx.Data.MarshalBinary()
+	if err != nil {
+		return nil, withCode(codeEncodingFailed, err)
+	}
+	return ed25519.Verify(tx.Sender[:], data, tx.Signature[:]), nil
+}
+
+// generateNonce returns a random nonce as a decimal string, since nonces do
+// not fit into a JavaScript number.
+func generateNonce(args []js.Value) (interface{}, error) {
+	return strconv.FormatUint(mazzaroth.GenerateNonce(), 10), nil
+}
@@ -6,23 +6,28 @@ import (
 	"github.com/kochavalabs/mazzaroth-go"
 )
 
-func mazzarothClient(this js.Value, args []js.Value) interface{} {
-	var client mazzaroth.Client
-	var err error
-
-	if len(args) == 0 {
-		// allow client to be created with default values
-		client, err = mazzaroth.NewMazzarothClient()
-		if err != nil {
-			return err.Error()
-		}
+func mazzarothClient(args []js.Value) (interface{}, error) {
+	// allow client to be created with default values
+	options := js.Undefined()
+	if len(args) > 0 {
+		options = args[0]
+	}
+	opts, err := parseClientOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := mazzaroth.NewMazzarothClient(opts.clientOptions()...)
+	if err != nil {
+		return nil, err
 	}
 
 	wrapperClient := &mazzarothJsWrapperClient{
-		client: client,
+		client:  client,
+		timeout: opts.timeout,
 	}
 
-	return js.ValueOf(map[string]interface{}{
+	return map[string]interface{}{
 		"BlockHeaderLookup": wrapperClient.blockHeaderLookup(),
 		"BlockHeaderList":   wrapperClient.blockHeaderList(),
 		"BlockHeight":       wrapperClient.blockHeight(),
@@ -32,20 +37,26 @@ func mazzarothClient(this js.Value, args []js.Value) interface{} {
 		"ReceiptLookup":     wrapperClient.receiptLookup(),
 		"TransactionLookup": wrapperClient.transactionLookup(),
 		"TransactionSubmit": wrapperClient.transactionSubmit(),
-	})
+	}, nil
 }
 
-func transactionBuilder(this js.Value, args []js.Value) interface{} {
+func transactionBuilder(args []js.Value) (interface{}, error) {
 	txBuilder := &transactionBuilderJsWrapper{}
-	return js.ValueOf(map[string]interface{}{
+	return map[string]interface{}{
 		"Call":     txBuilder.call(),
 		"Contract": txBuilder.contract(),
-	})
+	}, nil
 }
 
 func main() {
 	c := make(chan struct{})
-	js.Global().Set("NewMazzarothClient", js.FuncOf(mazzarothClient))
-	js.Global().Set("TransactionBuilder", js.FuncOf(transactionBuilder))
+	loadThrowIfError()
+	js.Global().Set("NewMazzarothClient", function(mazzarothClient))
+	js.Global().Set("TransactionBuilder", function(transactionBuilder))
+	js.Global().Set("GenerateKeyPair", function(generateKeyPair))
+	js.Global().Set("PublicKeyFromSeed", function(publicKeyFromSeed))
+	js.Global().Set("VerifySignature", function(verifySignature))
+	js.Global().Set("VerifyTransaction", function(verifyTransaction))
+	js.Global().Set("GenerateNonce", function(generateNonce))
 	<-c
 }
@@ -0,0 +1,210 @@
+// Type declarations for the mazzaroth-go wasm module, see mazzaroth.js.
+//
+// Hex strings are used for ids, hashes and signatures. Unsigned 64 bit
+// integers are encoded as decimal strings since they do not fit into a
+// JavaScript number, and functions accepting them take either form.
+
+export type Hex = string;
+export type Uint64 = string;
+
+export enum Status {
+  UNKNOWN = 0,
+  SUCCESS = 1,
+  FAILURE = 2,
+  PENDING = 3,
+  FINALIZED = 4,
+}
+
+export enum CategoryType {
+  UNKNOWN = 0,
+  CALL = 1,
+  DEPLOY = 2,
+  PAUSE = 3,
+  DELETE = 4,
+}
+
+export enum FunctionType {
+  UNKNOWN = 0,
+  READ = 1,
+  WRITE = 2,
+}
+
+export interface Parameter {
+  parameterName: string;
+  parameterType: string;
+}
+
+export interface FunctionSignature {
+  functionType: FunctionType;
+  functionName: string;
+  parameters: Parameter[] | null;
+  returns: Parameter[] | null;
+}
+
+export interface Abi {
+  version: string;
+  functions: FunctionSignature[] | null;
+}
+
+export interface Call {
+  function: string;
+  arguments: string[] | null;
+}
+
+export interface Contract {
+  version: string;
+  owner: Hex;
+  abi: Abi;
+  contractHash: Hex;
+  /** base64 encoded contract bytes */
+  contractBytes: string;
+}
+
+export type Category =
+  | { type: CategoryType.UNKNOWN; data: "" }
+  | { type: CategoryType.CALL; data: Call }
+  | { type: CategoryType.DEPLOY; data: Contract }
+  | { type: CategoryType.PAUSE; data: boolean }
+  | { type: CategoryType.DELETE; data: "" };
+
+export interface Data {
+  channelID: Hex;
+  nonce: Uint64;
+  blockExpirationNumber: Uint64;
+  category: Category;
+}
+
+export interface Transaction {
+  sender: Hex;
+  signature: Hex;
+  data: Data;
+}
+
+export interface Receipt {
+  transactionID: Hex;
+  status: Status;
+  stateRoot: Hex;
+  result: string;
+  statusInfo: string;
+}
+
+export interface BlockHeader {
+  blockHeight: Uint64;
+  transactionHeight: Uint64;
+  consensusSequenceNumber: Uint64;
+  transactionsMerkleRoot: Hex;
+  transactionsReceiptRoot: Hex;
+  stateRoot: Hex;
+  previousHeader: Hex;
+  status: Status;
+}
+
+export interface Block {
+  header: BlockHeader;
+  transactions: Transaction[] | null;
+}
+
+export interface BlockHeight {
+  height: Uint64;
+}
+
+export interface SubmitResult {
+  id: Hex;
+  receipt?: Receipt;
+}
+
+/** Code set on every Error thrown or rejected by the module. */
+export type ErrorCode =
+  | "INVALID_ARGUMENT"
+  | "REQUEST_FAILED"
+  | "ENCODING_FAILED"
+  | "SIGNING_FAILED";
+
+export interface MazzarothError extends Error {
+  code: ErrorCode;
+}
+
+export interface RetryOptions {
+  /** total number of attempts, defaults to 1 */
+  attempts?: number;
+  /** delay in milliseconds, multiplied by the attempt number */
+  backoff?: number;
+}
+
+export interface ClientOptions {
+  /** node address, defaults to http://localhost:6299 */
+  address?: string;
+  /** request timeout in milliseconds, defaults to 10000 */
+  timeout?: number;
+  /** headers set on every request */
+  headers?: Record<string, string>;
+  retry?: RetryOptions;
+}
+
+/** Client methods reject with a MazzarothError. */
+export interface MazzarothClient {
+  BlockHeaderLookup(channelID: Hex, blockID: string): Promise<BlockHeader>;
+  BlockHeaderList(channelID: Hex, blockHeight: number, number: number): Promise<BlockHeader[]>;
+  BlockHeight(channelID: Hex): Promise<BlockHeight>;
+  BlockLookup(channelID: Hex, blockID: string): Promise<Block>;
+  BlockList(channelID: Hex, blockHeight: number, number: number): Promise<Block[]>;
+  ChannelAbi(channelID: Hex): Promise<Abi>;
+  ReceiptLookup(channelID: Hex, transactionID: Hex): Promise<Receipt>;
+  TransactionLookup(channelID: Hex, transactionID: Hex): Promise<Transaction>;
+  TransactionSubmit(transaction: Transaction | string): Promise<SubmitResult>;
+}
+
+/**
+ * Builder methods throw a MazzarothError. Every step returns a new object, so
+ * intermediate steps can be kept and reused to build several transactions.
+ */
+export interface Signable {
+  /** signs with a hex encoded 32 byte ed25519 seed */
+  Sign(privateKey: Hex): Transaction;
+}
+
+export interface CallArguments {
+  Arguments(...args: string[]): Signable;
+}
+
+export interface CallFunction {
+  Function(name: string): CallArguments;
+}
+
+export interface ContractCategory {
+  /** abi is either an object or its json form, contract the wasm binary */
+  Deploy(owner: Hex, version: string, abi: Abi | string, contract: Uint8Array | ArrayBuffer | ArrayBufferView): Signable;
+  Delete(): Signable;
+  Pause(pause: boolean): Signable;
+}
+
+export interface TransactionBuilder {
+  Call(sender: Hex, channel: Hex, nonce: number | Uint64, blockExpirationNumber: number | Uint64): CallFunction;
+  Contract(sender: Hex, channel: Hex, nonce: number | Uint64, blockExpirationNumber: number | Uint64): ContractCategory;
+}
+
+export interface KeyPair {
+  /** hex encoded 32 byte ed25519 seed */
+  privateKey: Hex;
+  /** hex encoded public key, which is also the account xdr.ID */
+  publicKey: Hex;
+}
+
+export interface Mazzaroth {
+  NewMazzarothClient(options?: ClientOptions): MazzarothClient;
+  TransactionBuilder(): TransactionBuilder;
+  GenerateKeyPair(): KeyPair;
+  PublicKeyFromSeed(privateKey: Hex): Hex;
+  VerifySignature(publicKey: Hex, message: Hex, signature: Hex): boolean;
+  VerifyTransaction(transaction: Transaction | string): boolean;
+  GenerateNonce(): Uint64;
+}
+
+/**
+ * Instantiates mazzarothclient.wasm and resolves with its exports. The wasm
+ * binary is fetched next to this module unless source is given. Repeated
+ * calls resolve with the same instance.
+ */
+export function load(
+  source?: string | URL | Response | BufferSource | WebAssembly.Module,
+): Promise<Mazzaroth>;
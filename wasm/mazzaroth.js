@@ -0,0 +1,77 @@
+// ES module loader for mazzarothclient.wasm, see mazzaroth.d.ts for the
+// exported api. wasm_exec.js from the go distribution defines the Go runtime
+// glue and has to be served next to this file, which `make build-wasm` does.
+import "./wasm_exec.js";
+
+const exported = [
+  "NewMazzarothClient",
+  "TransactionBuilder",
+  "GenerateKeyPair",
+  "PublicKeyFromSeed",
+  "VerifySignature",
+  "VerifyTransaction",
+  "GenerateNonce",
+];
+
+// enums of the xdr types, matching the numbers used in their json form.
+export const Status = Object.freeze({ UNKNOWN: 0, SUCCESS: 1, FAILURE: 2, PENDING: 3, FINALIZED: 4 });
+export const CategoryType = Object.freeze({ UNKNOWN: 0, CALL: 1, DEPLOY: 2, PAUSE: 3, DELETE: 4 });
+export const FunctionType = Object.freeze({ UNKNOWN: 0, READ: 1, WRITE: 2 });
+
+let loading;
+
+// throwIfError wraps a go function so that an Error object returned from it
+// is thrown instead, which syscall/js can not do. It is handed to the go
+// program in a global, see errors_js.go.
+function throwIfError(fn) {
+  return function () {
+    const result = fn.apply(this, arguments);
+    if (result instanceof Error) {
+      throw result;
+    }
+    return result;
+  };
+}
+
+export function load(source = new URL("./mazzarothclient.wasm", import.meta.url)) {
+  if (!loading) {
+    loading = instantiate(source).catch((err) => {
+      loading = undefined;
+      throw err;
+    });
+  }
+  return loading;
+}
+
+async function instantiate(source) {
+  const go = new globalThis.Go();
+  const instance = await compile(source, go.importObject);
+
+  // run resolves once the go program exits, which it never does.
+  globalThis.__mazzarothThrowIfError = throwIfError;
+  go.run(instance);
+
+  const mazzaroth = {};
+  for (const name of exported) {
+    mazzaroth[name] = globalThis[name];
+  }
+  return Object.freeze(mazzaroth);
+}
+
+async function compile(source, importObject) {
+  if (source instanceof WebAssembly.Module) {
+    return WebAssembly.instantiate(source, importObject);
+  }
+  if (source instanceof ArrayBuffer || ArrayBuffer.isView(source)) {
+    return (await WebAssembly.instantiate(source, importObject)).instance;
+  }
+
+  const response = source instanceof Response ? source : await fetch(source);
+  if (!response.ok) {
+    throw new Error(`unable to fetch ${response.url}: ${response.status}`);
+  }
+  if (WebAssembly.instantiateStreaming && response.headers.get("Content-Type") === "application/wasm") {
+    return (await WebAssembly.instantiateStreaming(response, importObject)).instance;
+  }
+  return (await WebAssembly.instantiate(await response.arrayBuffer(), importObject)).instance;
+}
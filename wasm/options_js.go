@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"syscall/js"
+	"time"
+
+	"github.com/kochavalabs/mazzaroth-go"
+)
+
+// clientOptions holds the settings read from the options object passed to
+// NewMazzarothClient.
+type clientOptions struct {
+	address  string
+	timeout  time.Duration
+	headers  map[string]string
+	attempts int
+	backoff  time.Duration
+}
+
+// parseClientOptions reads an options object of the form
+//
+//	{
+//	  address: "https://node:6299",
+//	  timeout: 10000,
+//	  headers: { "X-Header": "value" },
+//	  retry: { attempts: 3, backoff: 250 }
+//	}
+//
+// where every property is optional and durations are in milliseconds.
+func parseClientOptions(v js.Value) (*clientOptions, error) {
+	opts := &clientOptions{
+		timeout:  defaultTimeout,
+		attempts: 1,
+	}
+	if v.IsUndefined() || v.IsNull() {
+		return opts, nil
+	}
+	if v.Type() != js.TypeObject {
+		return nil, invalidArgument("options must be an object")
+	}
+
+	if address := v.Get("address"); !address.IsUndefined() {
+		if address.Type() != js.TypeString {
+			return nil, invalidArgument("options.address must be a string")
+		}
+		opts.address = address.String()
+	}
+
+	if timeout := v.Get("timeout"); !timeout.IsUndefined() {
+		if timeout.Type() != js.TypeNumber || timeout.Float() <= 0 {
+			return nil, invalidArgument("options.timeout must be a positive number of milliseconds")
+		}
+		opts.timeout = time.Duration(timeout.Float() * float64(time.Millisecond))
+	}
+
+	if headers := v.Get("headers"); !headers.IsUndefined() {
+		if headers.Type() != js.TypeObject {
+			return nil, invalidArgument("options.headers must be an object")
+		}
+		opts.headers = make(map[string]string)
+		keys := js.Global().Get("Object").Call("keys", headers)
+		for i := 0; i < keys.Length(); i++ {
+			key := keys.Index(i).String()
+			value := headers.Get(key)
+			if value.Type() != js.TypeString {
+				return nil, invalidArgument("options.headers.%s must be a string", key)
+			}
+			opts.headers[key] = value.String()
+		}
+	}
+
+	if retry := v.Get("retry"); !retry.IsUndefined() {
+		if retry.Type() != js.TypeObject {
+			return nil, invalidArgument("options.retry must be an object")
+		}
+		if attempts := retry.Get("attempts"); !attempts.IsUndefined() {
+			if attempts.Type() != js.TypeNumber || attempts.Int() < 1 {
+				return nil, invalidArgument("options.retry.attempts must be a number greater than zero")
+			}
+			opts.attempts = attempts.Int()
+		}
+		if backoff := retry.Get("backoff"); !backoff.IsUndefined() {
+			if backoff.Type() != js.TypeNumber || backoff.Float() < 0 {
+				return nil, invalidArgument("options.retry.backoff must be a non negative number of milliseconds")
+			}
+			opts.backoff = time.Duration(backoff.Float() * float64(time.Millisecond))
+		}
+	}
+
+	return opts, nil
+}
+
+// clientOptions converts the parsed options into mazzaroth client options.
+func (o *clientOptions) clientOptions() []mazzaroth.Options {
+	var transport http.RoundTripper = http.DefaultTransport
+	if o.attempts > 1 {
+		transport = &retryTransport{attempts: o.attempts, backoff: o.backoff, next: transport}
+	}
+	if len(o.headers) > 0 {
+		transport = &headerTransport{headers: o.headers, next: transport}
+	}
+
+	options := []mazzaroth.Options{
+		mazzaroth.WithHttpClient(&http.Client{
+			Timeout:   o.timeout,
+			Transport: transport,
+		}),
+	}
+	if o.address != "" {
+		options = append(options, mazzaroth.WithAddress(o.address))
+	}
+	return options
+}
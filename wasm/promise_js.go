@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"syscall/js"
+	"time"
+)
+
+// promise returns a JavaScript Promise that settles with the result of fn,
+// which is given a context that expires after timeout.
+// fn runs in its own goroutine since blocking calls, such as the http
+// requests made by the client, wait on a fetch that can only complete once
+// control has been handed back to the JavaScript event loop.
+func promise(timeout time.Duration, fn func(ctx context.Context) (interface{}, error)) js.Value {
+	executor := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve, reject := args[0], args[1]
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			result, err := fn(ctx)
+			if err != nil {
+				reject.Invoke(newJsError(err, codeRequestFailed))
+				return
+			}
+			value, err := toJsValue(result)
+			if err != nil {
+				reject.Invoke(newJsError(err, codeEncodingFailed))
+				return
+			}
+			resolve.Invoke(value)
+		}()
+		return nil
+	})
+	// the executor is invoked synchronously by the Promise constructor
+	defer executor.Release()
+	return js.Global().Get("Promise").New(executor)
+}
+
+// rejected returns a Promise that is already rejected with err.
+func rejected(err error) js.Value {
+	return js.Global().Get("Promise").Call("reject", newJsError(err, codeInvalidArgument))
+}
+
+// toJsValue converts v into a native JavaScript value by round tripping it
+// through its json form, which keeps the hex encoding of the xdr types.
+func toJsValue(v interface{}) (js.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	return js.Global().Get("JSON").Call("parse", string(b)), nil
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"crypto/ed25519"
-	"encoding/json"
 	"syscall/js"
 
 	"github.com/kochavalabs/crypto"
@@ -13,24 +12,28 @@ type signer interface {
 	Sign(pk ed25519.PrivateKey) (*xdr.Transaction, error)
 }
 
-func sign(signer signer) js.Func {
-	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		privateKeyBytes, err := crypto.FromHex(args[0].String())
-		if err != nil {
-			return err.Error()
+func sign(signer signer) js.Value {
+	return function(func(args []js.Value) (interface{}, error) {
+		a := parseArgs(args, "privateKey")
+		privateKeyHex := a.string(0)
+		if a.err != nil {
+			return nil, a.err
+		}
+		privateKeyBytes, err := crypto.FromHex(privateKeyHex)
+		if err != nil || len(privateKeyBytes) != ed25519.SeedSize {
+			return nil, invalidArgument("privateKey must be a hex encoded %d byte seed", ed25519.SeedSize)
 		}
 		key := ed25519.NewKeyFromSeed(privateKeyBytes)
 
 		tx, err := signer.Sign(key)
 		if err != nil {
-			return err.Error()
+			return nil, withCode(codeSigningFailed, err)
 		}
 
-		txJson, err := json.Marshal(tx)
+		txValue, err := toJsValue(tx)
 		if err != nil {
-			return err.Error()
+			return nil, withCode(codeEncodingFailed, err)
 		}
-
-		return js.ValueOf(string(txJson))
+		return txValue, nil
 	})
 }
@@ -4,45 +4,40 @@ import (
 	"syscall/js"
 
 	"github.com/kochavalabs/mazzaroth-go"
-	"github.com/kochavalabs/mazzaroth-xdr/go-xdr/xdr"
 )
 
+// transactionBuilderJsWrapper exposes the transaction builders to JavaScript.
+// Every Call or Contract invocation starts from a new builder and every step
+// of the chain works on its own copy of it, so a partially built transaction
+// can be reused as the base of several others without them interfering.
 type transactionBuilderJsWrapper struct{}
 
-func (tb *transactionBuilderJsWrapper) call() js.Func {
-	callBuilder := &mazzaroth.CallBuilder{}
-	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		signer, err := xdr.IDFromHexString(args[0].String())
-		if err != nil {
-			return err.Error()
+func (tb *transactionBuilderJsWrapper) call() js.Value {
+	return function(func(args []js.Value) (interface{}, error) {
+		a := parseArgs(args, "sender", "channel", "nonce", "blockExpirationNumber")
+		signer, channel, nonce, blockExpirationNumber := a.id(0), a.id(1), a.uint64(2), a.uint64(3)
+		if a.err != nil {
+			return nil, a.err
 		}
-		channel, err := xdr.IDFromHexString(args[1].String())
-		if err != nil {
-			return err.Error()
-		}
-		callBuilder.Call(&signer, &channel, uint64(args[2].Int()), uint64(args[3].Int()))
+		callBuilder := new(mazzaroth.CallBuilder).Call(&signer, &channel, nonce, blockExpirationNumber)
 		return map[string]interface{}{
-			"Function": callFunction(callBuilder),
-		}
+			"Function": callFunction(*callBuilder),
+		}, nil
 	})
 }
 
-func (tb *transactionBuilderJsWrapper) contract() js.Func {
-	contractBuilder := &mazzaroth.ContractBuilder{}
-	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		signer, err := xdr.IDFromHexString(args[0].String())
-		if err != nil {
-			return err.Error()
+func (tb *transactionBuilderJsWrapper) contract() js.Value {
+	return function(func(args []js.Value) (interface{}, error) {
+		a := parseArgs(args, "sender", "channel", "nonce", "blockExpirationNumber")
+		signer, channel, nonce, blockExpirationNumber := a.id(0), a.id(1), a.uint64(2), a.uint64(3)
+		if a.err != nil {
+			return nil, a.err
 		}
-		channel, err := xdr.IDFromHexString(args[1].String())
-		if err != nil {
-			return err.Error()
-		}
-		contractBuilder.Contract(&signer, &channel, uint64(args[2].Int()), uint64(args[3].Int()))
+		contractBuilder := new(mazzaroth.ContractBuilder).Contract(&signer, &channel, nonce, blockExpirationNumber)
 		return map[string]interface{}{
-			"Deploy": deploy(contractBuilder),
-			"Delete": delete(contractBuilder),
-			"Pause":  pause(contractBuilder),
-		}
+			"Deploy": deploy(*contractBuilder),
+			"Delete": delete(*contractBuilder),
+			"Pause":  pause(*contractBuilder),
+		}, nil
 	})
 }
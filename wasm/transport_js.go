@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// headerTransport sets a fixed set of headers on every request.
+type headerTransport struct {
+	headers map[string]string
+	next    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// retryTransport retries requests that failed in transit or with a server
+// side status, waiting backoff times the attempt number between attempts.
+type retryTransport struct {
+	attempts int
+	backoff  time.Duration
+	next     http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var response *http.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		response, err = t.next.RoundTrip(attemptReq)
+		if attempt >= t.attempts || !retryable(response, err) {
+			return response, err
+		}
+		if response != nil {
+			response.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(time.Duration(attempt) * t.backoff):
+		}
+	}
+}
+
+// retryable reports whether a request that ended with response and err is
+// worth sending again.
+func retryable(response *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+}